@@ -0,0 +1,122 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+// batchResult is the outcome of patching one POM of a batch.
+type batchResult struct {
+	POM    string            `json:"pom"`
+	Counts pkg.SummaryCounts `json:"counts"`
+	Error  string            `json:"error,omitempty"`
+}
+
+type batchCLIFlags struct {
+	dryRun bool
+	output string
+}
+
+func batchCmd() *cobra.Command {
+	var flags batchCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "batch <manifest>",
+		Short: "Patch many POMs in place, each with its own patch and properties files",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+			manifest, err := pkg.ParseBatchManifest(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to parse the manifest: %w", err)
+			}
+
+			results := make([]batchResult, 0, len(manifest.Entries))
+			failed := 0
+			for _, entry := range manifest.Entries {
+				if err := cmd.Context().Err(); err != nil {
+					return fmt.Errorf("stopped after %d of %d entries: %w", len(results), len(manifest.Entries), err)
+				}
+				summary, err := applyBatchEntry(cmd, entry, flags.dryRun)
+				result := batchResult{POM: entry.POM, Counts: summary.Counts()}
+				if err != nil {
+					result.Error = err.Error()
+					failed++
+				}
+				results = append(results, result)
+			}
+
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(results, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal the results: %w", err)
+				}
+				fmt.Println(string(out))
+			} else {
+				for _, r := range results {
+					if r.Error != "" {
+						fmt.Printf("%s: failed: %s\n", r.POM, r.Error)
+						continue
+					}
+					fmt.Printf("%s: %d updated, %d added, %d properties changed, %d no-op\n", r.POM, r.Counts.Updated, r.Counts.Added, r.Counts.PropertiesChanged, r.Counts.Noop)
+				}
+				fmt.Printf("%d of %d POMs patched successfully\n", len(results)-failed, len(results))
+			}
+
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to patch %d of %d POMs", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Patch and report, but do not write any POM")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
+
+// applyBatchEntry patches the POM of the entry in place, unless dryRun is
+// set.
+func applyBatchEntry(cmd *cobra.Command, entry pkg.BatchEntry, dryRun bool) (*pkg.PatchSummary, error) {
+	summary := &pkg.PatchSummary{}
+	patches, err := pkg.ParsePatches(entry.PatchFile, "")
+	if err != nil {
+		return summary, fmt.Errorf("failed to parse patches: %w", err)
+	}
+	properties, err := pkg.ParseProperties(entry.PropertiesFile, "")
+	if err != nil {
+		return summary, fmt.Errorf("failed to parse properties: %w", err)
+	}
+
+	pomPath, err := resolvePOMPath(entry.POM)
+	if err != nil {
+		return summary, err
+	}
+	parsedPom, err := gopom.Parse(pomPath)
+	if err != nil {
+		return summary, fmt.Errorf("failed to parse the pom file: %w", err)
+	}
+	newPom, err := pkg.PatchProjectWithOptions(cmd.Context(), parsedPom, patches, properties, pkg.PatchOptions{Summary: summary})
+	if err != nil {
+		return summary, fmt.Errorf("failed to patch the pom file: %w", err)
+	}
+	if dryRun {
+		return summary, nil
+	}
+
+	out, err := newPom.Marshal()
+	if err != nil {
+		return summary, fmt.Errorf("failed to marshal the pom file: %w", err)
+	}
+	if err := writePOM(pomPath, out); err != nil {
+		return summary, fmt.Errorf("failed to write the pom file: %w", err)
+	}
+	return summary, nil
+}
@@ -0,0 +1,98 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type blameCLIFlags struct {
+	searchProperties   bool
+	noSearchProperties bool
+	output             string
+}
+
+func blameCmd() *cobra.Command {
+	var flags blameCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "blame <pom-file> <groupId:artifactId>",
+		Short: "Print every step of how the version of a dependency is resolved",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+			groupID, artifactID, ok := strings.Cut(args[1], ":")
+			if !ok || groupID == "" || artifactID == "" {
+				return fmt.Errorf("invalid dependency %q, use groupId:artifactId", args[1])
+			}
+
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parsedPom, err := gopom.Parse(pomPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse the pom file: %w", err)
+			}
+
+			search, err := shouldSearchProperties(cmd.Context(), flags.searchProperties, flags.noSearchProperties, pomPath, parsedPom)
+			if err != nil {
+				return err
+			}
+			b, err := pkg.BlameDependencyVersion(cmd.Context(), pomPath, parsedPom, groupID, artifactID, search)
+			if err != nil {
+				return err
+			}
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(b, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal the blame: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			fmt.Printf("%s:%s\n", b.GroupID, b.ArtifactID)
+			for _, s := range b.Steps {
+				switch s.Kind {
+				case pkg.BlameDependency:
+					if s.Value == "" {
+						fmt.Printf("  declared in %s without a version\n", s.File)
+					} else {
+						fmt.Printf("  declared in %s with version %s\n", s.File, s.Value)
+					}
+				case pkg.BlameManaged:
+					fmt.Printf("  managed in dependencyManagement of %s with version %s\n", s.File, s.Value)
+				case pkg.BlameProperty:
+					switch {
+					case s.File != "":
+						fmt.Printf("  property %s = %s in %s\n", s.Name, s.Value, s.File)
+					case s.Value != "":
+						fmt.Printf("  built-in property %s = %s\n", s.Name, s.Value)
+					default:
+						fmt.Printf("  property %s is not defined, it may come from a parent that is not on disk\n", s.Name)
+					}
+				case pkg.BlameBOM:
+					fmt.Printf("  may be managed by BOM %s:%s imported in %s\n", s.Name, s.Value, s.File)
+				}
+			}
+			if b.Version != "" {
+				fmt.Printf("  => %s\n", b.Version)
+			} else {
+				fmt.Printf("  => unresolved\n")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.searchProperties, "search-properties", false, "Also follow dependencyManagement and properties inherited from parent POMs found on disk, the default for modules of a multi-module build")
+	cmd.Flags().BoolVar(&flags.noSearchProperties, "no-search-properties", false, "Never follow parent POMs, even when the pom file is part of a multi-module build")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
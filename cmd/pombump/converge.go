@@ -0,0 +1,56 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type convergeCLIFlags struct {
+	output string
+}
+
+func convergeCmd() *cobra.Command {
+	var flags convergeCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "converge <root>",
+		Short: "Report dependencies with different versions across the modules of a reactor",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+
+			modules, err := pkg.FindModules(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to find modules: %w", err)
+			}
+			if len(modules) == 0 {
+				return fmt.Errorf("no pom.xml found under %s", args[0])
+			}
+
+			divergences := pkg.Converge(cmd.Context(), modules)
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(divergences, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal divergences: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			for _, d := range divergences {
+				fmt.Printf("%s:%s (target %s)\n", d.GroupID, d.ArtifactID, d.Target)
+				for _, v := range d.Versions {
+					fmt.Printf("  %s: %s\n", v.Module, v.Version)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package pombump
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type convertCLIFlags struct {
+	output string
+	shape  string
+}
+
+func convertCmd() *cobra.Command {
+	var flags convertCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "convert <list-file>",
+		Short: "Convert a list of group:artifact:version lines into a patch file",
+		Long: `Convert a list of group:artifact:version lines, e.g. from a spreadsheet or a
+scanner, into a --patch-file. With --shape properties the lines are
+property=value and a --properties-file is written instead. Use - to read the
+list from stdin.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.shape != "deps" && flags.shape != "properties" {
+				return fmt.Errorf("unsupported shape %q, use deps or properties", flags.shape)
+			}
+
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open the list: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+
+			var out []byte
+			if flags.shape == "deps" {
+				patches, err := pkg.ParseCoordinateList(in)
+				if err != nil {
+					return fmt.Errorf("failed to parse the list: %w", err)
+				}
+				if out, err = pkg.MarshalPatchFile(patches); err != nil {
+					return err
+				}
+			} else {
+				properties, err := pkg.ParsePropertyList(in)
+				if err != nil {
+					return fmt.Errorf("failed to parse the list: %w", err)
+				}
+				if out, err = pkg.MarshalPropertiesFile(properties); err != nil {
+					return err
+				}
+			}
+
+			if flags.output == "" {
+				_, err := cmd.OutOrStdout().Write(out)
+				return err
+			}
+			if err := pkg.WriteFile(flags.output, out, fileMode.requested()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", flags.output, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", "", "The file to write, stdout if not set")
+	cmd.Flags().StringVar(&flags.shape, "shape", "deps", "The kind of file to write: deps (a --patch-file) or properties (a --properties-file)")
+	return cmd
+}
@@ -0,0 +1,90 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type driftCLIFlags struct {
+	bom    string
+	output string
+}
+
+func driftCmd() *cobra.Command {
+	var flags driftCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "bom-drift <pom-file>",
+		Short: "Report dependencies pinned to other versions than the ones a BOM manages",
+		Long: `Report the dependencies of a POM with an explicit version that differs from
+the one the BOM given with --bom manages, newer and older separately. Pins
+older than the BOM can usually be removed to get the BOM version, newer ones
+are overrides to keep or drop on purpose.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.bom == "" {
+				return fmt.Errorf("no BOM provided, use --bom")
+			}
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+
+			bom, err := gopom.Parse(flags.bom)
+			if err != nil {
+				return fmt.Errorf("failed to parse the BOM file: %w", err)
+			}
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parsedPom, err := gopom.Parse(pomPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse the pom file: %w", err)
+			}
+			if !pkg.ImportsBOM(parsedPom, bom) {
+				clog.FromContext(cmd.Context()).Warnf("%s does not import the BOM %s:%s", pomPath, bom.GroupID, bom.ArtifactID)
+			}
+
+			drifts := pkg.BOMDrift(cmd.Context(), pomPath, parsedPom, pkg.ManagedVersions(bom))
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(drifts, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal the drift: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			for _, kind := range []struct {
+				kind  pkg.DriftKind
+				title string
+			}{
+				{pkg.DriftNewer, "Newer than the BOM:"},
+				{pkg.DriftOlder, "Older than the BOM:"},
+				{pkg.DriftDifferent, "Not comparable to the BOM:"},
+			} {
+				printed := false
+				for _, d := range drifts {
+					if d.Kind != kind.kind {
+						continue
+					}
+					if !printed {
+						fmt.Println(kind.title)
+						printed = true
+					}
+					fmt.Printf("  %s:%s: %s, the BOM has %s\n", d.GroupID, d.ArtifactID, d.Version, d.BOMVersion)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.bom, "bom", "", "The BOM pom file to compare the versions to")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
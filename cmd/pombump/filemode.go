@@ -0,0 +1,69 @@
+package pombump
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+// fileModeFlag is an octal file mode flag, e.g. 0640.
+type fileModeFlag struct {
+	mode fs.FileMode
+	// set is true if the flag was given, rather than left at its default.
+	set bool
+}
+
+func (f *fileModeFlag) String() string {
+	return fmt.Sprintf("%#o", f.mode)
+}
+
+func (f *fileModeFlag) Set(s string) error {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return fmt.Errorf("invalid file mode %q, use octal permissions like 0644", s)
+	}
+	f.mode = fs.FileMode(mode)
+	f.set = true
+	return nil
+}
+
+func (f *fileModeFlag) Type() string {
+	return "mode"
+}
+
+var (
+	fileMode           = fileModeFlag{mode: 0o644}
+	allowWorldWritable bool
+)
+
+// checkFileMode rejects world-writable file modes unless they are allowed.
+func checkFileMode() error {
+	if fileMode.mode&0o002 != 0 && !allowWorldWritable {
+		return fmt.Errorf("file mode %s is world-writable, use --allow-world-writable to use it anyway", fileMode.String())
+	}
+	return nil
+}
+
+// requested returns the --file-mode permissions for pkg.WriteFile, zero if
+// the flag was not given so that the umask applies.
+func (f *fileModeFlag) requested() fs.FileMode {
+	if !f.set {
+		return 0
+	}
+	return f.mode
+}
+
+// appendFile appends data to path, creating it with the --file-mode
+// permissions if it does not exist yet.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode.mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
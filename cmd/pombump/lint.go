@@ -0,0 +1,79 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type lintCLIFlags struct {
+	output          string
+	failOn          string
+	disableWarnings string
+}
+
+func lintCmd() *cobra.Command {
+	var flags lintCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "lint <pom-file>",
+		Short: "Report POM hygiene issues without patching",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+			failOn, err := pkg.ParseSeverity(flags.failOn)
+			if err != nil {
+				return err
+			}
+			disabled, err := pkg.ParseCodes(flags.disableWarnings)
+			if err != nil {
+				return err
+			}
+
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parsedPom, err := gopom.Parse(pomPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse the pom file: %w", err)
+			}
+
+			findings := pkg.Lint(parsedPom, disabled...)
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(findings, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal findings: %w", err)
+				}
+				fmt.Println(string(out))
+			} else {
+				for _, f := range findings {
+					fmt.Printf("[%s] %s %s: %s\n", f.Severity, f.Code, f.Check, f.Message)
+				}
+			}
+
+			failed := 0
+			for _, f := range findings {
+				if f.Severity.AtLeast(failOn) {
+					failed++
+				}
+			}
+			if failed > 0 {
+				// Findings are not a usage error.
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d issue(s) with severity %s or higher", failed, failOn)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	cmd.Flags().StringVar(&flags.failOn, "fail-on", string(pkg.SeverityWarning), "Exit non-zero if there are findings of this severity or higher: info, warning or error")
+	cmd.Flags().StringVar(&flags.disableWarnings, "disable-warnings", "", "A comma-separated list of check codes to skip, e.g. PB001,PB007")
+	return cmd
+}
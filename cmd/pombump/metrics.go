@@ -0,0 +1,36 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chainguard-dev/pombump/pkg"
+)
+
+// metrics are the counters and phase timings of a run, written out as JSON
+// with --metrics-file.
+type metrics struct {
+	POMsParsed        int                `json:"pomsParsed"`
+	PropertiesFound   int                `json:"propertiesFound"`
+	PatchesApplied    int                `json:"patchesApplied"`
+	PropertiesApplied int                `json:"propertiesApplied"`
+	TimingsSeconds    map[string]float64 `json:"timingsSeconds"`
+}
+
+func newMetrics() *metrics {
+	return &metrics{TimingsSeconds: map[string]float64{}}
+}
+
+// observe adds the time since start to the named phase.
+func (m *metrics) observe(phase string, start time.Time) {
+	m.TimingsSeconds[phase] += time.Since(start).Seconds()
+}
+
+func (m *metrics) write(path string) error {
+	out, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	return pkg.WriteFile(path, append(out, '\n'), fileMode.requested())
+}
@@ -0,0 +1,79 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type policyCLIFlags struct {
+	policy string
+	output string
+}
+
+func policyCmd() *cobra.Command {
+	var flags policyCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "policy <pom-file>",
+		Short: "Check the dependencies of a POM against a policy of banned dependencies and minimum versions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.policy == "" {
+				return fmt.Errorf("no policy provided, use --policy")
+			}
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+
+			policy, err := pkg.ParsePolicy(flags.policy)
+			if err != nil {
+				return fmt.Errorf("failed to parse policy: %w", err)
+			}
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parsedPom, err := gopom.Parse(pomPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse the pom file: %w", err)
+			}
+
+			violations := pkg.CheckPolicy(cmd.Context(), pomPath, parsedPom, policy)
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(violations, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal violations: %w", err)
+				}
+				fmt.Println(string(out))
+			} else {
+				for _, v := range violations {
+					switch v.Kind {
+					case pkg.ViolationBanned:
+						if v.Reason == "" {
+							fmt.Printf("[%s] %s:%s\n", v.Kind, v.GroupID, v.ArtifactID)
+						} else {
+							fmt.Printf("[%s] %s:%s: %s\n", v.Kind, v.GroupID, v.ArtifactID, v.Reason)
+						}
+					case pkg.ViolationBelowMinimum:
+						fmt.Printf("[%s] %s:%s: %s is below %s\n", v.Kind, v.GroupID, v.ArtifactID, v.Version, v.Minimum)
+					}
+				}
+			}
+
+			if len(violations) > 0 {
+				// Violations are not a usage error.
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d policy violation(s)", len(violations))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.policy, "policy", "", "The policy file with banned dependencies and minimum versions")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
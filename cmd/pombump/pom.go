@@ -0,0 +1,62 @@
+package pombump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+)
+
+// resolvePOMPath returns the pom.xml inside path if path is a directory, and
+// path itself otherwise.
+func resolvePOMPath(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.IsDir() {
+		// Let the parser report problems with the file itself.
+		return path, nil
+	}
+	pom := filepath.Join(path, "pom.xml")
+	if _, err := os.Stat(pom); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("directory %s does not contain a pom.xml", path)
+		}
+		return "", err
+	}
+	return pom, nil
+}
+
+// writePOM writes the marshaled pom to path, keeping the permissions of the
+// file it replaces unless --file-mode is given.
+func writePOM(path string, out []byte) error {
+	if fileMode.set {
+		return pkg.WriteFile(path, append(out, '\n'), fileMode.mode)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(out, '\n'), fi.Mode().Perm())
+}
+
+// shouldSearchProperties returns whether parent POMs are followed. When
+// neither --search-properties nor --no-search-properties is given, they are
+// for poms whose declared parent is on disk, see pkg.InReactor.
+func shouldSearchProperties(ctx context.Context, search, noSearch bool, path string, project *gopom.Project) (bool, error) {
+	switch {
+	case search && noSearch:
+		return false, fmt.Errorf("use either --search-properties or --no-search-properties")
+	case search || noSearch:
+		return search, nil
+	}
+	if pkg.InReactor(path, project) {
+		clog.FromContext(ctx).Infof("Searching parent POMs, %s is part of a multi-module build, use --no-search-properties to disable", path)
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,81 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type resolveCLIFlags struct {
+	searchProperties   bool
+	noSearchProperties bool
+	output             string
+}
+
+func resolveCmd() *cobra.Command {
+	var flags resolveCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "resolve <pom-file> <groupId:artifactId>",
+		Short: "Print the effective version of a dependency and where it comes from",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+			groupID, artifactID, ok := strings.Cut(args[1], ":")
+			if !ok || groupID == "" || artifactID == "" {
+				return fmt.Errorf("invalid dependency %q, use groupId:artifactId", args[1])
+			}
+
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parsedPom, err := gopom.Parse(pomPath)
+			if err != nil {
+				return fmt.Errorf("failed to parse the pom file: %w", err)
+			}
+
+			search, err := shouldSearchProperties(cmd.Context(), flags.searchProperties, flags.noSearchProperties, pomPath, parsedPom)
+			if err != nil {
+				return err
+			}
+			r, err := pkg.Resolve(cmd.Context(), pomPath, parsedPom, groupID, artifactID, search)
+			if err != nil {
+				return err
+			}
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(r, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal the resolution: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+
+			switch {
+			case r.Declared == "":
+				fmt.Printf("%s:%s: unresolved, no version in %s, it is managed by a parent or BOM\n", r.GroupID, r.ArtifactID, pomPath)
+			case r.Source == pkg.SourceInline:
+				fmt.Printf("%s:%s: %s, inline in %s\n", r.GroupID, r.ArtifactID, r.Version, r.DeclaredIn)
+			case r.Source == pkg.SourceProperty && r.PropertyIn != "":
+				fmt.Printf("%s:%s: %s, from property %s in %s\n", r.GroupID, r.ArtifactID, r.Version, r.Property, r.PropertyIn)
+			case r.Source == pkg.SourceProperty:
+				fmt.Printf("%s:%s: %s, from built-in property %s\n", r.GroupID, r.ArtifactID, r.Version, r.Property)
+			default:
+				fmt.Printf("%s:%s: unresolved, %s in %s references properties that are not defined, they may come from an external parent\n", r.GroupID, r.ArtifactID, r.Declared, r.DeclaredIn)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.searchProperties, "search-properties", false, "Also follow versions and properties inherited from parent POMs found on disk, the default for modules of a multi-module build")
+	cmd.Flags().BoolVar(&flags.noSearchProperties, "no-search-properties", false, "Never follow parent POMs, even when the pom file is part of a multi-module build")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
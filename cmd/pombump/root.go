@@ -1,12 +1,16 @@
 package pombump
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"chainguard.dev/apko/pkg/log"
 	charmlog "github.com/charmbracelet/log"
 
+	"github.com/chainguard-dev/clog"
 	"github.com/chainguard-dev/gopom"
 	"github.com/chainguard-dev/pombump/pkg"
 	"github.com/spf13/cobra"
@@ -18,19 +22,66 @@ type rootCLIFlags struct {
 	properties     string
 	patchFile      string
 	propertiesFile string
+	constraints    string
+	groupVersions  string
+	embedded       bool
+	trimBOM        string
+
+	outputDeps       string
+	outputProperties string
+	overwriteOutput  bool
+	metricsFile      string
+	changelog        string
+	changelogTmpl    string
+	reportJSON       bool
+	output           string
+
+	patchManagedDependencies bool
+	bumpPolicy               string
+	dmOnly                   bool
+	noAdd                    bool
+	regexMatch               bool
+	sortProperties           bool
+	applyScopeType           bool
+	strictVersions           bool
+	verifyRoundTrip          bool
+	inPlace                  bool
+	tee                      bool
 }
 
 var rootFlags rootCLIFlags
 
+// applyResult is what --output json prints instead of the pom file.
+type applyResult struct {
+	// Path is the pom file that was written.
+	Path     string       `json:"path"`
+	Changes  []pkg.Change `json:"changes"`
+	Warnings []string     `json:"warnings"`
+}
+
 func New() *cobra.Command {
 	var logPolicy []string
 	var level log.CharmLogLevel
+	var timeout time.Duration
+	cancel := context.CancelFunc(func() {})
+	// Finalizers run even when the command fails, unlike the post-run hooks.
+	cobra.OnFinalize(func() { cancel() })
 
 	cmd := &cobra.Command{
 		Use:   "pombump <file-to-bump>",
 		Short: "pombump cli",
 		Args:  cobra.ExactArgs(1),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkFileMode(); err != nil {
+				return err
+			}
+			if timeout > 0 {
+				// The context is already canceled on Ctrl-C, this adds a
+				// deadline to it.
+				var ctx context.Context
+				ctx, cancel = context.WithTimeout(cmd.Context(), timeout)
+				cmd.SetContext(ctx)
+			}
 			out, err := log.Writer(logPolicy)
 			if err != nil {
 				return fmt.Errorf("failed to create log writer: %w", err)
@@ -43,9 +94,13 @@ func New() *cobra.Command {
 		// Uncomment the following line if your bare application
 		// has an action associated with it:
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if rootFlags.output != "text" && rootFlags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", rootFlags.output)
+			}
 			if rootFlags.dependencies == "" && rootFlags.properties == "" &&
-				rootFlags.patchFile == "" && rootFlags.propertiesFile == "" {
-				return fmt.Errorf("no dependencies or properties provides, use --dependencies/--patch-file or --properties/properties-file")
+				rootFlags.patchFile == "" && rootFlags.propertiesFile == "" &&
+				rootFlags.constraints == "" && rootFlags.groupVersions == "" && !rootFlags.embedded {
+				return fmt.Errorf("no dependencies or properties provides, use --dependencies/--patch-file, --properties/properties-file, --constraints, --group-versions or --embedded")
 			}
 
 			if rootFlags.patchFile != "" && rootFlags.dependencies != "" {
@@ -54,7 +109,40 @@ func New() *cobra.Command {
 			if rootFlags.propertiesFile != "" && rootFlags.properties != "" {
 				return fmt.Errorf("use either --properties or --properties-file")
 			}
+			if rootFlags.tee && !rootFlags.inPlace {
+				return fmt.Errorf("--tee can only be used with --in-place")
+			}
+			if rootFlags.output == "json" && (!rootFlags.inPlace || rootFlags.tee) {
+				return fmt.Errorf("--output json prints the result instead of the pom file, use it with --in-place and without --tee")
+			}
 
+			ctx := cmd.Context()
+			var warnings *warningRecorder
+			if rootFlags.output == "json" {
+				warnings = newWarningRecorder(slog.Default().Handler())
+				ctx = clog.WithLogger(ctx, clog.New(warnings))
+			}
+
+			bumpPolicy, err := pkg.ParseBumpPolicy(rootFlags.bumpPolicy)
+			if err != nil {
+				return err
+			}
+			if rootFlags.changelogTmpl == "" {
+				rootFlags.changelogTmpl = pkg.DefaultChangelogTemplate
+			}
+			changelogTmpl, err := pkg.ParseChangelogTemplate(rootFlags.changelogTmpl)
+			if err != nil {
+				return err
+			}
+
+			m := newMetrics()
+			runStart := time.Now()
+
+			// The context only carries the --timeout deadline and Ctrl-C,
+			// check it between the phases.
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("stopped before parsing: %w", err)
+			}
 			patches, err := pkg.ParsePatches(rootFlags.patchFile, rootFlags.dependencies)
 			if err != nil {
 				return fmt.Errorf("failed to parse patches: %w", err)
@@ -65,35 +153,217 @@ func New() *cobra.Command {
 				return fmt.Errorf("failed to parse properties: %w", err)
 			}
 
-			parsedPom, err := gopom.Parse(args[0])
+			pomPath, err := resolvePOMPath(args[0])
+			if err != nil {
+				return err
+			}
+			parseStart := time.Now()
+			parsedPom, err := gopom.Parse(pomPath)
 			if err != nil {
 				return fmt.Errorf("failed to parse the pom file: %w", err)
 			}
+			m.observe("parse", parseStart)
+			m.POMsParsed++
+			if parsedPom.Properties != nil {
+				m.PropertiesFound = len(parsedPom.Properties.Entries)
+			}
+
+			var embedded *pkg.Embedded
+			if rootFlags.embedded {
+				embedded, err = pkg.ParseEmbedded(pomPath)
+				if err != nil {
+					return fmt.Errorf("failed to parse the embedded patches: %w", err)
+				}
+				if embedded == nil {
+					return fmt.Errorf("%s has no pombump comment block", pomPath)
+				}
+				// Explicit patches and properties win over the embedded ones.
+				patches = pkg.MergePatches(patches, embedded.Patches)
+				for k, v := range embedded.Properties {
+					if _, ok := propertiesPatches[k]; !ok {
+						propertiesPatches[k] = v
+					}
+				}
+			}
+
+			if rootFlags.constraints != "" {
+				constraints, err := pkg.ParseConstraints(rootFlags.constraints)
+				if err != nil {
+					return fmt.Errorf("failed to parse constraints: %w", err)
+				}
+				// Explicit patches win over the constraints.
+				patches = pkg.MergePatches(patches, pkg.ConstraintPatches(ctx, parsedPom, constraints))
+			}
+			if rootFlags.groupVersions != "" {
+				groups, err := pkg.ParseGroupVersions(rootFlags.groupVersions)
+				if err != nil {
+					return fmt.Errorf("failed to parse group versions: %w", err)
+				}
+				// Explicit patches, and the constraints, win over the group
+				// versions.
+				patches = pkg.MergePatches(patches, pkg.GroupVersionPatches(ctx, parsedPom, groups))
+			}
 
-			newPom, err := pkg.PatchProject(cmd.Context(), parsedPom, patches, propertiesPatches)
+			summary := &pkg.PatchSummary{}
+			opts := pkg.PatchOptions{
+				Summary:                  summary,
+				PatchManagedDependencies: rootFlags.patchManagedDependencies,
+				BumpPolicy:               bumpPolicy,
+				DependencyManagementOnly: rootFlags.dmOnly,
+				NoAdd:                    rootFlags.noAdd,
+				RegexMatch:               rootFlags.regexMatch,
+				StrictVersions:           rootFlags.strictVersions,
+				SortProperties:           rootFlags.sortProperties,
+				ApplyScopeType:           rootFlags.applyScopeType,
+			}
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("stopped before patching: %w", err)
+			}
+			patchStart := time.Now()
+			newPom, err := pkg.PatchProjectWithOptions(ctx, parsedPom, patches, propertiesPatches, opts)
 			if err != nil {
 				return fmt.Errorf("failed to patch the pom file: %w", err)
 			}
+			if rootFlags.trimBOM != "" {
+				bom, err := gopom.Parse(rootFlags.trimBOM)
+				if err != nil {
+					return fmt.Errorf("failed to parse the BOM file: %w", err)
+				}
+				removed, err := pkg.TrimManagedDependencies(ctx, newPom, bom)
+				if err != nil {
+					return err
+				}
+				for _, dep := range removed {
+					summary.Record(pkg.Change{Kind: pkg.ChangeRemoved, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, From: dep.Version})
+				}
+			}
+			m.observe("patch", patchStart)
+			counts := summary.Counts()
+			m.PatchesApplied = counts.Updated + counts.Added
+			m.PropertiesApplied = counts.PropertiesChanged
 
 			out, err := newPom.Marshal()
 			if err != nil {
 				return fmt.Errorf("failed to marshal the pom file: %w", err)
 			}
-			fmt.Println(string(out))
+			if rootFlags.verifyRoundTrip {
+				if err := pkg.VerifyRoundTrip(newPom, out); err != nil {
+					return fmt.Errorf("failed to verify the pom file round trip: %w", err)
+				}
+			}
+			// Keep the comment block, so that the pom can be patched again.
+			if embedded != nil {
+				out = pkg.InsertComment(out, embedded.Comment)
+			}
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("stopped before writing: %w", err)
+			}
+			if rootFlags.inPlace {
+				if err := writePOM(pomPath, out); err != nil {
+					return fmt.Errorf("failed to write the pom file: %w", err)
+				}
+			}
+			if !rootFlags.inPlace || rootFlags.tee {
+				fmt.Println(string(out))
+			}
+
+			if rootFlags.outputDeps != "" {
+				if err := pkg.WritePatchFile(rootFlags.outputDeps, summary.AppliedPatches(patches), fileMode.requested(), rootFlags.overwriteOutput); err != nil {
+					return fmt.Errorf("failed to write the dependencies file: %w", err)
+				}
+			}
+			if rootFlags.outputProperties != "" {
+				if err := pkg.WritePropertiesFile(rootFlags.outputProperties, summary.AppliedProperties(), fileMode.requested(), rootFlags.overwriteOutput); err != nil {
+					return fmt.Errorf("failed to write the properties file: %w", err)
+				}
+			}
+
+			if rootFlags.changelog != "" {
+				entry, err := pkg.Changelog(summary, changelogTmpl)
+				if err != nil {
+					return err
+				}
+				if err := appendFile(rootFlags.changelog, entry); err != nil {
+					return fmt.Errorf("failed to write the changelog: %w", err)
+				}
+			}
+
+			if rootFlags.reportJSON {
+				report, err := json.Marshal(summary.Counts())
+				if err != nil {
+					return fmt.Errorf("failed to marshal the report: %w", err)
+				}
+				fmt.Fprintln(cmd.ErrOrStderr(), string(report))
+			}
+
+			if rootFlags.output == "json" {
+				result := applyResult{Path: pomPath, Changes: summary.Changes, Warnings: warnings.Warnings()}
+				if result.Changes == nil {
+					result.Changes = []pkg.Change{}
+				}
+				out, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal the result: %w", err)
+				}
+				fmt.Println(string(out))
+			}
+
+			if rootFlags.metricsFile != "" {
+				m.observe("total", runStart)
+				if err := m.write(rootFlags.metricsFile); err != nil {
+					return fmt.Errorf("failed to write the metrics file: %w", err)
+				}
+			}
 			return nil
 		},
 	}
 	cmd.PersistentFlags().StringSliceVar(&logPolicy, "log-policy", []string{"builtin:stderr"}, "log policy (e.g. builtin:stderr, /tmp/log/foo)")
 	cmd.PersistentFlags().Var(&level, "log-level", "log level (e.g. debug, info, warn, error)")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Fail if the command takes longer than this, e.g. 30s or 5m, no limit if 0")
+	cmd.PersistentFlags().Var(&fileMode, "file-mode", "The octal permissions of the files that are written, e.g. 0640. Pom files written in place keep their permissions unless this is given")
+	cmd.PersistentFlags().BoolVar(&allowWorldWritable, "allow-world-writable", false, "Allow a world-writable --file-mode")
 
+	cmd.AddCommand(lintCmd())
+	cmd.AddCommand(convergeCmd())
+	cmd.AddCommand(statsCmd())
+	cmd.AddCommand(policyCmd())
+	cmd.AddCommand(resolveCmd())
+	cmd.AddCommand(blameCmd())
+	cmd.AddCommand(batchCmd())
+	cmd.AddCommand(convertCmd())
+	cmd.AddCommand(validateCmd())
+	cmd.AddCommand(driftCmd())
 	cmd.AddCommand(version.WithFont("starwars"))
 
 	cmd.DisableAutoGenTag = true
 
 	flagSet := cmd.Flags()
-	flagSet.StringVar(&rootFlags.dependencies, "dependencies", "", "A space-separated list of dependencies to update in form groupID@artifactID@version")
+	flagSet.StringVar(&rootFlags.dependencies, "dependencies", "", "A space-separated list of dependencies to update in form groupID@artifactID@version or groupID:artifactID:version")
 	flagSet.StringVar(&rootFlags.properties, "properties", "", "A space-separated list of properties to update in form property@value")
 	flagSet.StringVar(&rootFlags.patchFile, "patch-file", "", "The input file to read patches from")
 	flagSet.StringVar(&rootFlags.propertiesFile, "properties-file", "", "The input file to read properties from")
+	flagSet.StringVar(&rootFlags.constraints, "constraints", "", "A file with approved versions, dependencies in the pom file that are older are bumped to them")
+	flagSet.StringVar(&rootFlags.groupVersions, "group-versions", "", "A file with versions by groupId prefix, dependencies in the pom file under a prefix are set to its version")
+	flagSet.BoolVar(&rootFlags.embedded, "embedded", false, "Also apply the patches and properties in the pombump comment block of the pom file")
+	flagSet.StringVar(&rootFlags.trimBOM, "trim-management", "", "A BOM pom file, dependencyManagement entries it manages at the same version are removed after patching")
+	flagSet.StringVar(&rootFlags.outputDeps, "output-deps", "", "Also write the applied dependency patches to this file, in --patch-file format")
+	flagSet.StringVar(&rootFlags.outputProperties, "output-properties", "", "Also write the applied properties to this file, in --properties-file format")
+	flagSet.BoolVar(&rootFlags.overwriteOutput, "overwrite-output", false, "Replace the --output-deps and --output-properties files instead of merging into them")
+	flagSet.StringVar(&rootFlags.changelog, "changelog", "", "Append a line for every change to this file, e.g. a Markdown changelog")
+	flagSet.StringVar(&rootFlags.changelogTmpl, "changelog-template", "", "The text/template rendering each change for --changelog, e.g. '- {{.ArtifactID}} {{.To}}'")
+	flagSet.StringVar(&rootFlags.metricsFile, "metrics-file", "", "Write counters and phase timings of the run to this file as JSON")
+	flagSet.BoolVar(&rootFlags.reportJSON, "report-json", false, "Print a one line JSON summary of the changes (updated, added, removed, propertiesChanged, noop) to stderr")
+	flagSet.BoolVar(&rootFlags.patchManagedDependencies, "patch-managed-dependencies", false, "Also set the version on versionless dependencies that are managed in dependencyManagement")
+	flagSet.BoolVar(&rootFlags.dmOnly, "dm-only", false, "Only update and add versions in dependencyManagement, never in dependencies")
+	flagSet.BoolVar(&rootFlags.noAdd, "no-add", false, "Only update dependencies that are already in the pom file, never add the ones that are missing")
+	flagSet.BoolVar(&rootFlags.regexMatch, "regex-match", false, "Treat the artifactID of the patches as a regular expression matched against existing dependencies in the same group, never adding new ones")
+	flagSet.BoolVar(&rootFlags.applyScopeType, "apply-scope-type", false, "Also change the scope and type of the dependencies that are patched to the ones of the patch, other than the import and jar defaults")
+	flagSet.BoolVar(&rootFlags.sortProperties, "sort-properties", false, "Write the properties of the patched pom file sorted by name, instead of keeping their order and adding new ones at the end")
+	flagSet.BoolVar(&rootFlags.strictVersions, "strict-versions", false, "Fail instead of warning when a patch or property sets a LATEST or RELEASE version")
+	flagSet.BoolVar(&rootFlags.verifyRoundTrip, "verify-roundtrip", false, "Before writing, re-parse the output and fail if it does not match the patched pom")
+	flagSet.BoolVar(&rootFlags.inPlace, "in-place", false, "Write the patched pom file back to where it was read from instead of printing it")
+	flagSet.BoolVar(&rootFlags.tee, "tee", false, "With --in-place, also print the patched pom file")
+	flagSet.StringVar(&rootFlags.output, "output", "text", "Output format: text prints the patched pom file, json prints the changes and warnings, and requires --in-place")
+	flagSet.StringVar(&rootFlags.bumpPolicy, "bump-policy", "major", "The largest version change allowed for existing dependencies: patch, minor or major")
 	return cmd
 }
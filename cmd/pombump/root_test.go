@@ -0,0 +1,36 @@
+package pombump
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRootTimeout(t *testing.T) {
+	in, err := os.ReadFile("../../pkg/testdata/zookeeper.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pomPath := filepath.Join(t.TempDir(), "pom.xml")
+	if err := os.WriteFile(pomPath, in, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := New()
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"--timeout", "1ns", "--in-place", "--dependencies", "io.netty@netty-handler@4.1.118.Final", pomPath})
+	if err := cmd.ExecuteContext(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Execute() with an expired timeout = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	out, err := os.ReadFile(pomPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(in) {
+		t.Errorf("Execute() with an expired timeout wrote the pom file")
+	}
+}
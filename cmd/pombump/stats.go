@@ -0,0 +1,54 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type statsCLIFlags struct {
+	output string
+}
+
+func statsCmd() *cobra.Command {
+	var flags statsCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "stats <root>",
+		Short: "Report how many modules use each dependency and with how many versions",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+
+			modules, err := pkg.FindModules(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to find modules: %w", err)
+			}
+			if len(modules) == 0 {
+				return fmt.Errorf("no pom.xml found under %s", args[0])
+			}
+
+			stats := pkg.Stats(cmd.Context(), modules)
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(stats, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal stats: %w", err)
+				}
+				fmt.Println(string(out))
+				return nil
+			}
+			for _, s := range stats {
+				fmt.Printf("%s:%s: %d module(s), %d version(s) %s\n", s.GroupID, s.ArtifactID, s.Modules, len(s.Versions), strings.Join(s.Versions, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
@@ -0,0 +1,73 @@
+package pombump
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainguard-dev/pombump/pkg"
+	"github.com/spf13/cobra"
+)
+
+type validateCLIFlags struct {
+	shape  string
+	output string
+}
+
+func validateCmd() *cobra.Command {
+	var flags validateCLIFlags
+
+	cmd := &cobra.Command{
+		Use:   "validate-patches <file>",
+		Short: "Check a patch or properties file for problems without a POM",
+		Long: `Check a --patch-file for patches without coordinates or a version, and for
+dependencies patched more than once, possibly with conflicting versions. With
+--shape properties the file is a --properties-file instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.shape != "deps" && flags.shape != "properties" {
+				return fmt.Errorf("unsupported shape %q, use deps or properties", flags.shape)
+			}
+			if flags.output != "text" && flags.output != "json" {
+				return fmt.Errorf("unsupported output format %q, use text or json", flags.output)
+			}
+
+			var problems []pkg.Problem
+			if flags.shape == "deps" {
+				patches, err := pkg.ParsePatches(args[0], "")
+				if err != nil {
+					return fmt.Errorf("failed to parse patches: %w", err)
+				}
+				problems = pkg.ValidatePatches(patches)
+			} else {
+				properties, err := pkg.ParseProperties(args[0], "")
+				if err != nil {
+					return fmt.Errorf("failed to parse properties: %w", err)
+				}
+				problems = pkg.ValidateProperties(properties)
+			}
+
+			if flags.output == "json" {
+				out, err := json.MarshalIndent(problems, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal problems: %w", err)
+				}
+				fmt.Println(string(out))
+			} else {
+				for _, p := range problems {
+					fmt.Printf("%s: %s\n", p.Entry, p.Message)
+				}
+			}
+
+			if len(problems) > 0 {
+				// Problems are not a usage error.
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d problem(s) in %s", len(problems), args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.shape, "shape", "deps", "The kind of file: deps (a --patch-file) or properties (a --properties-file)")
+	cmd.Flags().StringVar(&flags.output, "output", "text", "Output format: text or json")
+	return cmd
+}
@@ -0,0 +1,45 @@
+package pombump
+
+import (
+	"context"
+	"log/slog"
+)
+
+// warningRecorder is a slog.Handler that passes records on to the wrapped
+// handler, and keeps the messages of the warnings and errors.
+type warningRecorder struct {
+	slog.Handler
+	messages *[]string
+}
+
+func newWarningRecorder(h slog.Handler) *warningRecorder {
+	return &warningRecorder{Handler: h, messages: &[]string{}}
+}
+
+func (w *warningRecorder) Enabled(ctx context.Context, level slog.Level) bool {
+	// Warnings are recorded even if the log level hides them.
+	return level >= slog.LevelWarn || w.Handler.Enabled(ctx, level)
+}
+
+func (w *warningRecorder) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		*w.messages = append(*w.messages, r.Message)
+	}
+	if !w.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return w.Handler.Handle(ctx, r)
+}
+
+func (w *warningRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningRecorder{Handler: w.Handler.WithAttrs(attrs), messages: w.messages}
+}
+
+func (w *warningRecorder) WithGroup(name string) slog.Handler {
+	return &warningRecorder{Handler: w.Handler.WithGroup(name), messages: w.messages}
+}
+
+// Warnings returns the messages of the warnings and errors logged so far.
+func (w *warningRecorder) Warnings() []string {
+	return *w.messages
+}
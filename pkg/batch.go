@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// BatchManifest is the format of a batch manifest: the POMs to patch, each
+// with its own patch and properties files. Relative paths are relative to
+// the manifest.
+type BatchManifest struct {
+	Entries []BatchEntry `json:"entries" yaml:"entries"`
+}
+
+// BatchEntry is one POM of a batch manifest.
+type BatchEntry struct {
+	POM            string `json:"pom" yaml:"pom"`
+	PatchFile      string `json:"patchFile,omitempty" yaml:"patchFile,omitempty"`
+	PropertiesFile string `json:"propertiesFile,omitempty" yaml:"propertiesFile,omitempty"`
+}
+
+// ParseBatchManifest reads a batch manifest, making the paths in it relative
+// to the current directory.
+func ParseBatchManifest(path string) (*BatchManifest, error) {
+	var manifest BatchManifest
+	if err := decodeFile(path, &manifest); err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	rel := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	for i, e := range manifest.Entries {
+		if e.POM == "" {
+			return nil, fmt.Errorf("entry %d has no pom", i)
+		}
+		if e.PatchFile == "" && e.PropertiesFile == "" {
+			return nil, fmt.Errorf("entry for %s has neither a patchFile nor a propertiesFile", e.POM)
+		}
+		manifest.Entries[i] = BatchEntry{POM: rel(e.POM), PatchFile: rel(e.PatchFile), PropertiesFile: rel(e.PropertiesFile)}
+	}
+	return &manifest, nil
+}
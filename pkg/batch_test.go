@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseBatchManifest(t *testing.T) {
+	got, err := ParseBatchManifest("testdata/batch.yaml")
+	if err != nil {
+		t.Fatalf("ParseBatchManifest() = %v", err)
+	}
+	want := &BatchManifest{Entries: []BatchEntry{
+		{POM: "testdata/trino.pom.xml", PatchFile: "testdata/trino-patches.yaml"},
+		{POM: "testdata/zookeeper.pom.xml", PatchFile: "testdata/patches.yaml", PropertiesFile: "/tmp/properties.yaml"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseBatchManifest() (-want +got)\n%s", diff)
+	}
+
+	testCases := []struct {
+		name string
+		path string
+	}{{
+		name: "missing file",
+		path: "testdata/missing",
+	}, {
+		name: "entry without patches or properties",
+		path: "testdata/invalid-batch.yaml",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseBatchManifest(tc.path); err == nil {
+				t.Errorf("ParseBatchManifest(%s) did not fail", tc.path)
+			}
+		})
+	}
+}
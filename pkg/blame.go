@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+// BlameKind is the kind of a BlameStep.
+type BlameKind string
+
+const (
+	// BlameDependency is the dependency declaration, Value is its version as
+	// written (empty if it has none).
+	BlameDependency BlameKind = "dependency"
+	// BlameManaged is the dependencyManagement entry that gives the version.
+	BlameManaged BlameKind = "managed"
+	// BlameProperty is a property referenced on the way to the version. A
+	// property without a File is a built-in, or is undefined if it also has
+	// no Value.
+	BlameProperty BlameKind = "property"
+	// BlameBOM is an imported BOM that may manage the version, when nothing
+	// on disk does.
+	BlameBOM BlameKind = "bom"
+)
+
+// BlameStep is one step in how the version of a dependency is resolved.
+type BlameStep struct {
+	Kind  BlameKind `json:"kind"`
+	File  string    `json:"file,omitempty"`
+	Name  string    `json:"name,omitempty"`
+	Value string    `json:"value,omitempty"`
+}
+
+// Blame is the provenance of the version of a dependency.
+type Blame struct {
+	GroupID    string      `json:"groupId"`
+	ArtifactID string      `json:"artifactId"`
+	Steps      []BlameStep `json:"steps"`
+	// Version is the effective version, empty if it could not be resolved.
+	Version string `json:"version,omitempty"`
+}
+
+// BlameDependencyVersion traces where the version of groupID:artifactID in the
+// project at path comes from: the declaration, the dependencyManagement
+// entry, and every property referenced along the way, or the imported BOMs
+// that may manage it. If searchParents is set, the parents that can be found
+// on disk are followed too. Returns an error if the dependency is not
+// declared at all.
+func BlameDependencyVersion(ctx context.Context, path string, project *gopom.Project, groupID, artifactID string, searchParents bool) (*Blame, error) {
+	chain := []Module{{Path: path, Project: project}}
+	if searchParents {
+		chain = parentChain(ctx, path, project)
+	}
+	d, found := findDeclaration(chain, groupID, artifactID)
+	if !found {
+		return nil, fmt.Errorf("%s:%s is not declared in %s", groupID, artifactID, path)
+	}
+	b := &Blame{GroupID: groupID, ArtifactID: artifactID, Steps: []BlameStep{}}
+	if d.dependencyIn != "" {
+		b.Steps = append(b.Steps, BlameStep{Kind: BlameDependency, File: d.dependencyIn, Value: d.dependency})
+	}
+	if d.managed {
+		b.Steps = append(b.Steps, BlameStep{Kind: BlameManaged, File: d.versionIn, Value: d.version})
+	}
+	declared := d.version
+
+	if declared == "" {
+		for _, m := range chain {
+			for _, dep := range managedDependencies(m.Project) {
+				if isBOMImport(dep) {
+					b.Steps = append(b.Steps, BlameStep{Kind: BlameBOM, File: m.Path, Name: dep.GroupID + ":" + dep.ArtifactID, Value: dep.Version})
+				}
+			}
+		}
+		return b, nil
+	}
+
+	props := chainProperties(chain)
+	b.Steps = append(b.Steps, blameProperties(chain, props, declared, map[string]bool{})...)
+	if version, ok := resolveVersion(declared, props); ok {
+		b.Version = version
+	}
+	return b, nil
+}
+
+// blameProperties returns a step for every property referenced by value,
+// recursively, each followed by the steps of its own value.
+func blameProperties(chain []Module, props map[string]string, value string, seen map[string]bool) []BlameStep {
+	var steps []BlameStep
+	for _, m := range propertyRefRe.FindAllStringSubmatch(value, -1) {
+		name := m[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		steps = append(steps, BlameStep{Kind: BlameProperty, File: propertyIn(chain, name), Name: name, Value: props[name]})
+		steps = append(steps, blameProperties(chain, props, props[name], seen)...)
+	}
+	return steps
+}
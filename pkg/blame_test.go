@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlameDependencyVersion(t *testing.T) {
+	module := filepath.Join("testdata", "blame", "module", "pom.xml")
+	parent := filepath.Join("testdata", "blame", "module", "..", "pom.xml")
+	project, err := gopom.Parse(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name       string
+		groupID    string
+		artifactID string
+		want       *Blame
+		wantErr    bool
+	}{{
+		name:       "inline",
+		groupID:    "com.google.guava",
+		artifactID: "guava",
+		want: &Blame{GroupID: "com.google.guava", ArtifactID: "guava", Version: "33.0.0-jre", Steps: []BlameStep{
+			{Kind: BlameDependency, File: module, Value: "33.0.0-jre"},
+		}},
+	}, {
+		name:       "managed by the parent through nested properties",
+		groupID:    "io.netty",
+		artifactID: "netty-handler",
+		want: &Blame{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final", Steps: []BlameStep{
+			{Kind: BlameDependency, File: module},
+			{Kind: BlameManaged, File: parent, Value: "${netty.version}"},
+			{Kind: BlameProperty, File: parent, Name: "netty.version", Value: "${netty.minor}.118.Final"},
+			{Kind: BlameProperty, File: parent, Name: "netty.minor", Value: "4.1"},
+		}},
+	}, {
+		name:       "imported bom",
+		groupID:    "com.fasterxml.jackson.core",
+		artifactID: "jackson-databind",
+		want: &Blame{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Steps: []BlameStep{
+			{Kind: BlameDependency, File: module},
+			{Kind: BlameBOM, File: parent, Name: "com.fasterxml.jackson:jackson-bom", Value: "2.18.0"},
+		}},
+	}, {
+		name:       "undefined property",
+		groupID:    "org.yaml",
+		artifactID: "snakeyaml",
+		want: &Blame{GroupID: "org.yaml", ArtifactID: "snakeyaml", Steps: []BlameStep{
+			{Kind: BlameDependency, File: module, Value: "${snakeyaml.version}"},
+			{Kind: BlameProperty, Name: "snakeyaml.version"},
+		}},
+	}, {
+		name:       "not declared",
+		groupID:    "org.json",
+		artifactID: "json",
+		wantErr:    true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := BlameDependencyVersion(context.Background(), module, project, tc.groupID, tc.artifactID, true)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BlameDependencyVersion() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("BlameDependencyVersion() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// ManagedVersions returns the versions managed by a BOM, keyed by
+// groupId:artifactId. Versions that reference the BOM's own properties (or
+// ${project.version}) are resolved, ones that can not be resolved are left
+// out.
+func ManagedVersions(bom *gopom.Project) map[string]string {
+	props := chainProperties([]Module{{Project: bom}})
+	managed := map[string]string{}
+	for _, dep := range managedDependencies(bom) {
+		version, ok := resolveVersion(dep.Version, props)
+		if !ok || version == "" {
+			continue
+		}
+		managed[dep.GroupID+":"+dep.ArtifactID] = version
+	}
+	return managed
+}
+
+// isBOMImport returns true if the dependency imports a BOM.
+func isBOMImport(dep gopom.Dependency) bool {
+	return dep.Scope == "import" && dep.Type == "pom"
+}
+
+// isAggregator returns true if the dependency is a pom that is depended on
+// (scope compile) to get its dependencies, rather than imported as a BOM.
+func isAggregator(dep gopom.Dependency) bool {
+	return dep.Type == "pom" && (dep.Scope == "" || dep.Scope == "compile")
+}
+
+// managesMore returns true if a dependencyManagement entry manages more than
+// the version, e.g. exclusions, so that removing it changes the build even
+// when a BOM manages the same version.
+func managesMore(dep gopom.Dependency) bool {
+	return isBOMImport(dep) || dep.Exclusions != nil || dep.Classifier != "" || (dep.Scope != "" && dep.Scope != "compile")
+}
+
+// TrimManagedDependencies removes the DependencyManagement entries of the
+// project that are redundant because the BOM manages them at exactly the same
+// version. The project must import the BOM, otherwise the entries are the
+// only versions it has and are left alone. Returns the removed entries.
+func TrimManagedDependencies(ctx context.Context, project *gopom.Project, bom *gopom.Project) ([]gopom.Dependency, error) {
+	if !ImportsBOM(project, bom) {
+		return nil, fmt.Errorf("the project does not import the BOM %s:%s, not trimming its dependencyManagement", bom.GroupID, bom.ArtifactID)
+	}
+	return trimManagedDependencies(ctx, project, ManagedVersions(bom)), nil
+}
+
+// trimManagedDependencies removes the DependencyManagement entries that the
+// managed versions make redundant. To be conservative, entries whose version
+// comes from a property are always kept, as are BOM imports and entries that
+// say more than the version: exclusions, a classifier or a scope.
+func trimManagedDependencies(ctx context.Context, project *gopom.Project, managed map[string]string) []gopom.Dependency {
+	log := clog.FromContext(ctx)
+	if project.DependencyManagement == nil || project.DependencyManagement.Dependencies == nil {
+		return nil
+	}
+
+	var removed []gopom.Dependency
+	kept := []gopom.Dependency{}
+	for _, dep := range *project.DependencyManagement.Dependencies {
+		key := dep.GroupID + ":" + dep.ArtifactID
+		if v, ok := managed[key]; ok && dep.Version != "" && v == dep.Version && !strings.Contains(dep.Version, "${") && !managesMore(dep) {
+			log.Infof("Removing %s:%s from dependencyManagement, the BOM manages the same version", key, dep.Version)
+			removed = append(removed, dep)
+			continue
+		}
+		kept = append(kept, dep)
+	}
+	*project.DependencyManagement.Dependencies = kept
+	return removed
+}
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestManagedVersions(t *testing.T) {
+	bom, err := gopom.Parse("testdata/bom.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"io.netty:netty-handler":    "4.1.118.Final",
+		"io.netty:netty-codec-http": "4.1.118.Final",
+		"io.netty:netty-tcnative":   "2.0.70.Final",
+	}
+	if diff := cmp.Diff(want, ManagedVersions(bom)); diff != "" {
+		t.Errorf("ManagedVersions() (-want +got)\n%s", diff)
+	}
+}
+
+func TestManagedVersionsProperties(t *testing.T) {
+	bom := &gopom.Project{
+		Version: "1.0.0",
+		Properties: &gopom.Properties{Entries: map[string]string{
+			"netty.version": "${netty.major}.118.Final",
+			"netty.major":   "4.1",
+			"loop.a":        "${loop.b}",
+			"loop.b":        "${loop.a}",
+		}},
+		DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{
+			{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "${netty.version}"},
+			{GroupID: "x", ArtifactID: "own", Version: "${project.version}"},
+			{GroupID: "x", ArtifactID: "cycle", Version: "${loop.a}"},
+			{GroupID: "x", ArtifactID: "missing", Version: "${missing.version}"},
+		}},
+	}
+	want := map[string]string{
+		"io.netty:netty-handler": "4.1.118.Final",
+		"x:own":                  "1.0.0",
+	}
+	if diff := cmp.Diff(want, ManagedVersions(bom)); diff != "" {
+		t.Errorf("ManagedVersions() (-want +got)\n%s", diff)
+	}
+}
+
+func TestTrimManagedDependencies(t *testing.T) {
+	bom, err := gopom.Parse("testdata/bom.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := gopom.Dependency{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final"}
+	project := &gopom.Project{
+		Properties: &gopom.Properties{Entries: map[string]string{"netty.version": "4.1.118.Final"}},
+		DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{
+			makeDep("io.netty", "netty-bom", "4.1.118.Final", "import", "pom"),
+			// Same version as the BOM, redundant.
+			handler,
+			// Different version than the BOM, an override.
+			{GroupID: "io.netty", ArtifactID: "netty-codec-http", Version: "4.1.117.Final"},
+			// Same version, but through a property.
+			{GroupID: "io.netty", ArtifactID: "netty-tcnative", Version: "${netty.version}"},
+			// Not managed by the BOM.
+			{GroupID: "org.json", ArtifactID: "json", Version: "20231013"},
+			// Not managed by the BOM, and only has exclusions.
+			{GroupID: "x", ArtifactID: "only-exclusions", Exclusions: &[]gopom.Exclusion{{GroupID: "y", ArtifactID: "z"}}},
+			// Same version as the BOM, but with exclusions.
+			{GroupID: "io.netty", ArtifactID: "netty-buffer", Version: "4.1.118.Final", Exclusions: &[]gopom.Exclusion{{GroupID: "y", ArtifactID: "z"}}},
+			// Same version as the BOM, but with a classifier.
+			{GroupID: "io.netty", ArtifactID: "netty-transport-native-epoll", Version: "4.1.118.Final", Classifier: "linux-x86_64"},
+			// Same version as the BOM, but with a scope.
+			{GroupID: "io.netty", ArtifactID: "netty-common", Version: "4.1.118.Final", Scope: "test"},
+		}},
+	}
+	want := append([]gopom.Dependency{}, (*project.DependencyManagement.Dependencies)[:1]...)
+	want = append(want, (*project.DependencyManagement.Dependencies)[2:]...)
+	// The BOM fixture does not manage these, pretend it does.
+	managed := ManagedVersions(bom)
+	for _, a := range []string{"netty-buffer", "netty-transport-native-epoll", "netty-common"} {
+		managed["io.netty:"+a] = "4.1.118.Final"
+	}
+	removed := trimManagedDependencies(context.Background(), project, managed)
+	if diff := cmp.Diff([]gopom.Dependency{handler}, removed); diff != "" {
+		t.Errorf("removed (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(want, *project.DependencyManagement.Dependencies); diff != "" {
+		t.Errorf("kept (-want +got)\n%s", diff)
+	}
+}
+
+func TestTrimManagedDependenciesNotImported(t *testing.T) {
+	bom, err := gopom.Parse("testdata/bom.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name     string
+		imported bool
+	}{{
+		name:     "imported",
+		imported: true,
+	}, {
+		name: "not imported",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deps := []gopom.Dependency{{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final"}}
+			if tc.imported {
+				deps = append(deps, makeDep("io.netty", "netty-bom", "4.1.118.Final", "import", "pom"))
+			}
+			project := &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &deps}}
+			removed, err := TrimManagedDependencies(context.Background(), project, bom)
+			if !tc.imported {
+				if err == nil {
+					t.Errorf("TrimManagedDependencies() with a BOM that is not imported did not fail")
+				}
+				if len(*project.DependencyManagement.Dependencies) != 1 {
+					t.Errorf("TrimManagedDependencies() with a BOM that is not imported removed %v", removed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TrimManagedDependencies() = %v", err)
+			}
+			if len(removed) != 1 {
+				t.Errorf("TrimManagedDependencies() removed %v, want netty-handler", removed)
+			}
+		})
+	}
+}
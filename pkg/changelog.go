@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// DefaultChangelogTemplate renders a change as a Markdown bullet, e.g.
+// "- Bump io.netty:netty-handler from 4.1.94.Final to 4.1.118.Final".
+const DefaultChangelogTemplate = `- {{if eq .Kind "added"}}Add {{.GroupID}}:{{.ArtifactID}} {{.To}}` +
+	`{{else if eq .Kind "removed"}}Remove {{.GroupID}}:{{.ArtifactID}} {{.From}} from dependencyManagement` +
+	`{{else if eq .Kind "property"}}Bump property {{.Property}}{{if .From}} from {{.From}}{{end}} to {{.To}}` +
+	`{{else if .RenamedFrom}}Replace {{.RenamedFrom.GroupID}}:{{.RenamedFrom.ArtifactID}}{{if .From}} {{.From}}{{end}} with {{.GroupID}}:{{.ArtifactID}} {{.To}}` +
+	`{{else}}Bump {{.GroupID}}:{{.ArtifactID}}{{if .From}} from {{.From}}{{end}} to {{.To}}{{end}}` +
+	`{{if .Reason}} ({{.Reason}}){{end}}`
+
+// ParseChangelogTemplate parses a text/template that renders a single
+// Change as a line of the changelog.
+func ParseChangelogTemplate(s string) (*template.Template, error) {
+	t, err := template.New("changelog").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the changelog template: %w", err)
+	}
+	return t, nil
+}
+
+// Changelog renders every change in the summary, except the no-ops, with t,
+// one line each.
+func Changelog(summary *PatchSummary, t *template.Template) ([]byte, error) {
+	if summary == nil {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	for _, c := range summary.Changes {
+		if c.Kind == ChangeNoop {
+			continue
+		}
+		if err := t.Execute(&buf, c); err != nil {
+			return nil, fmt.Errorf("failed to render the changelog: %w", err)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
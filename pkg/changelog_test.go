@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangelog(t *testing.T) {
+	summary := &PatchSummary{Changes: []Change{
+		{Kind: ChangeUpdated, GroupID: "io.netty", ArtifactID: "netty-handler", From: "4.1.94.Final", To: "4.1.118.Final", Reason: "CVE-2025-24970"},
+		{Kind: ChangeUpdated, GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api", From: "4.0.1", To: "6.0.0", RenamedFrom: &Coordinate{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api"}},
+		{Kind: ChangeAdded, GroupID: "org.json", ArtifactID: "json", To: "20231013"},
+		{Kind: ChangeRemoved, GroupID: "ch.qos.logback", ArtifactID: "logback-core", From: "1.4.12"},
+		{Kind: ChangeProperty, Property: "jetty.version", From: "9.4.52.v20230823", To: "9.4.53.v20231009"},
+		{Kind: ChangeNoop, GroupID: "a", ArtifactID: "b", To: "1.0.0"},
+	}}
+
+	testCases := []struct {
+		name string
+		tmpl string
+		want string
+	}{{
+		name: "default",
+		tmpl: DefaultChangelogTemplate,
+		want: `- Bump io.netty:netty-handler from 4.1.94.Final to 4.1.118.Final (CVE-2025-24970)
+- Replace javax.servlet:javax.servlet-api 4.0.1 with jakarta.servlet:jakarta.servlet-api 6.0.0
+- Add org.json:json 20231013
+- Remove ch.qos.logback:logback-core 1.4.12 from dependencyManagement
+- Bump property jetty.version from 9.4.52.v20230823 to 9.4.53.v20231009
+`,
+	}, {
+		name: "custom",
+		tmpl: `* {{.Kind}} {{or .Property .ArtifactID}} {{or .To .From}}`,
+		want: `* updated netty-handler 4.1.118.Final
+* updated jakarta.servlet-api 6.0.0
+* added json 20231013
+* removed logback-core 1.4.12
+* property jetty.version 9.4.53.v20231009
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := ParseChangelogTemplate(tc.tmpl)
+			if err != nil {
+				t.Fatalf("ParseChangelogTemplate() = %v", err)
+			}
+			got, err := Changelog(summary, tmpl)
+			if err != nil {
+				t.Fatalf("Changelog() = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				t.Errorf("Changelog() (-want +got)\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ParseChangelogTemplate("{{.Kind"); err == nil {
+		t.Errorf("ParseChangelogTemplate() with an invalid template did not fail")
+	}
+}
@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// ConstraintList is the format of a constraints file: the approved versions
+// for a set of dependencies.
+type ConstraintList struct {
+	Constraints []Constraint `json:"constraints" yaml:"constraints"`
+}
+
+// Constraint is the approved (minimum) version of a dependency.
+type Constraint struct {
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Version    string `json:"version" yaml:"version"`
+}
+
+// ParseConstraints reads a constraints file.
+func ParseConstraints(constraintsFile string) ([]Constraint, error) {
+	var constraintList ConstraintList
+	if err := decodeFile(constraintsFile, &constraintList); err != nil {
+		return nil, err
+	}
+	for _, c := range constraintList.Constraints {
+		if c.GroupID == "" || c.ArtifactID == "" || c.Version == "" {
+			return nil, fmt.Errorf("invalid constraint %s:%s:%s, groupId, artifactId and version are required", c.GroupID, c.ArtifactID, c.Version)
+		}
+	}
+	return constraintList.Constraints, nil
+}
+
+// ConstraintPatches returns the patches that align the dependencies already in
+// the project to the constraints. A dependency is only bumped if its current
+// version is lower than the approved one, it is never downgraded, and
+// dependencies that are not in the project are not added. Versions that can
+// not be compared (e.g. property references) are left alone.
+func ConstraintPatches(ctx context.Context, project *gopom.Project, constraints []Constraint) []Patch {
+	log := clog.FromContext(ctx)
+
+	approved := make(map[string]Constraint, len(constraints))
+	for _, c := range constraints {
+		approved[c.GroupID+":"+c.ArtifactID] = c
+	}
+
+	patches := []Patch{}
+	seen := map[string]bool{}
+	for _, dep := range allDependencies(project) {
+		key := dep.GroupID + ":" + dep.ArtifactID
+		c, ok := approved[key]
+		if !ok || dep.Version == "" || seen[key] {
+			continue
+		}
+		cmp, ok := compareVersions(dep.Version, c.Version)
+		if !ok {
+			log.Warnf("Can not compare %s version %s to the approved %s, leaving it alone", key, dep.Version, c.Version)
+			continue
+		}
+		if cmp >= 0 {
+			log.Debugf("%s version %s is already at or above the approved %s", key, dep.Version, c.Version)
+			continue
+		}
+		log.Infof("Aligning %s from %s to the approved %s", key, dep.Version, c.Version)
+		seen[key] = true
+		patches = append(patches, Patch{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: c.Version, Scope: dep.Scope, Type: dep.Type})
+	}
+	return patches
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseConstraints(t *testing.T) {
+	got, err := ParseConstraints("testdata/constraints.yaml")
+	if err != nil {
+		t.Fatalf("ParseConstraints() = %v", err)
+	}
+	if len(got) != 5 {
+		t.Errorf("ParseConstraints() got %d constraints, want 5", len(got))
+	}
+	if _, err := ParseConstraints("testdata/invalid-constraints.yaml"); err == nil {
+		t.Errorf("ParseConstraints() with a missing version did not fail")
+	}
+	if _, err := ParseConstraints("testdata/missing"); err == nil {
+		t.Errorf("ParseConstraints() with a missing file did not fail")
+	}
+}
+
+func TestConstraintPatches(t *testing.T) {
+	constraints, err := ParseConstraints("testdata/constraints.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedPom, err := gopom.Parse("testdata/cloudwatch-exporter.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Patch{{GroupID: "org.yaml", ArtifactID: "snakeyaml", Version: "2.2"}}
+	if diff := cmp.Diff(want, ConstraintPatches(context.Background(), parsedPom, constraints)); diff != "" {
+		t.Errorf("ConstraintPatches() (-want +got)\n%s", diff)
+	}
+}
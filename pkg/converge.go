@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// ModuleVersion is the version of a dependency in one module.
+type ModuleVersion struct {
+	Module  string `json:"module"`
+	Version string `json:"version"`
+}
+
+// Divergence is a dependency that is pinned to different versions in
+// different modules of a reactor.
+type Divergence struct {
+	GroupID    string          `json:"groupId"`
+	ArtifactID string          `json:"artifactId"`
+	Versions   []ModuleVersion `json:"versions"`
+	// Target is the highest of the versions, the suggested version to
+	// converge on.
+	Target string `json:"target"`
+}
+
+// DependencyVersions collects the effective version of every dependency that
+// is declared with a version (in dependencies or dependencyManagement) in
+// each module, keyed by groupId:artifactId. Property references are resolved
+// with the module's EffectiveProperties, dependencies whose version can not
+// be resolved are skipped.
+func DependencyVersions(ctx context.Context, modules []Module) map[string][]ModuleVersion {
+	log := clog.FromContext(ctx)
+
+	versions := map[string][]ModuleVersion{}
+	for _, m := range modules {
+		props := EffectiveProperties(ctx, m.Path, m.Project)
+		seen := map[string]bool{}
+		for _, dep := range allDependencies(m.Project) {
+			if dep.Version == "" {
+				continue
+			}
+			version, ok := resolveVersion(dep.Version, props)
+			if !ok {
+				log.Debugf("Skipping %s:%s in %s, can not resolve version %s", dep.GroupID, dep.ArtifactID, m.Path, dep.Version)
+				continue
+			}
+			key := dep.GroupID + ":" + dep.ArtifactID
+			if seen[key+"@"+version] {
+				continue
+			}
+			seen[key+"@"+version] = true
+			versions[key] = append(versions[key], ModuleVersion{Module: m.Path, Version: version})
+		}
+	}
+	return versions
+}
+
+// Converge returns the dependencies that have more than one version across
+// the modules, sorted by groupId and artifactId.
+func Converge(ctx context.Context, modules []Module) []Divergence {
+	divergences := []Divergence{}
+	for key, versions := range DependencyVersions(ctx, modules) {
+		if len(distinctVersions(versions)) < 2 {
+			continue
+		}
+		c := coordinate(key)
+		divergences = append(divergences, Divergence{GroupID: c.GroupID, ArtifactID: c.ArtifactID, Versions: versions, Target: highestVersion(versions)})
+	}
+	sort.Slice(divergences, func(i, j int) bool {
+		if divergences[i].GroupID != divergences[j].GroupID {
+			return divergences[i].GroupID < divergences[j].GroupID
+		}
+		return divergences[i].ArtifactID < divergences[j].ArtifactID
+	})
+	return divergences
+}
+
+// coordinate splits a groupId:artifactId key.
+func coordinate(key string) Coordinate {
+	groupID, artifactID, _ := strings.Cut(key, ":")
+	return Coordinate{GroupID: groupID, ArtifactID: artifactID}
+}
+
+func distinctVersions(versions []ModuleVersion) []string {
+	seen := map[string]bool{}
+	var distinct []string
+	for _, v := range versions {
+		if !seen[v.Version] {
+			seen[v.Version] = true
+			distinct = append(distinct, v.Version)
+		}
+	}
+	return distinct
+}
+
+// highestVersion returns the highest of the versions that can be compared.
+func highestVersion(versions []ModuleVersion) string {
+	highest := ""
+	for _, v := range versions {
+		if highest == "" {
+			if _, ok := versionSegments(v.Version); ok {
+				highest = v.Version
+			}
+			continue
+		}
+		if c, ok := compareVersions(v.Version, highest); ok && c > 0 {
+			highest = v.Version
+		}
+	}
+	return highest
+}
@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConverge(t *testing.T) {
+	modules, err := FindModules(context.Background(), "testdata/reactor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moduleA := filepath.Join("testdata", "reactor", "module-a", "pom.xml")
+	moduleB := filepath.Join("testdata", "reactor", "module-b", "pom.xml")
+	moduleC := filepath.Join("testdata", "reactor", "module-c", "pom.xml")
+	parent := filepath.Join("testdata", "reactor", "pom.xml")
+	want := []Divergence{{
+		GroupID:    "com.fasterxml.jackson.core",
+		ArtifactID: "jackson-databind",
+		Versions:   []ModuleVersion{{Module: moduleC, Version: "2.17.2"}, {Module: parent, Version: "2.18.0"}},
+		Target:     "2.18.0",
+	}, {
+		GroupID:    "io.netty",
+		ArtifactID: "netty-handler",
+		Versions:   []ModuleVersion{{Module: moduleA, Version: "4.1.118.Final"}, {Module: moduleB, Version: "4.1.94.Final"}},
+		Target:     "4.1.118.Final",
+	}}
+	if diff := cmp.Diff(want, Converge(context.Background(), modules)); diff != "" {
+		t.Errorf("Converge() (-want +got)\n%s", diff)
+	}
+}
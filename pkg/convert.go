@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseCoordinateList reads a list of dependencies, one per line, as
+// group:artifact:version (or pombump's group@artifact@version[@scope[@type]])
+// and returns them as patches with the default scope and type. Blank lines
+// and lines starting with # are skipped. Versions with a colon or whitespace
+// are rejected, they are most likely Maven's group:artifact:type:version or a
+// trailing scope, which would otherwise end up in the version.
+func ParseCoordinateList(r io.Reader) ([]Patch, error) {
+	patches := []Patch{}
+	err := scanLines(r, func(n int, line string) error {
+		parts := splitCoordinates(line)
+		if len(parts) < 3 || len(parts) > 5 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return fmt.Errorf("line %d: invalid coordinate %q, must be group:artifact:version", n, line)
+		}
+		if strings.ContainsAny(parts[2], ": \t") {
+			return fmt.Errorf("line %d: invalid version %q in %q, must be group:artifact:version without type, classifier or scope", n, parts[2], line)
+		}
+		p := Patch{GroupID: parts[0], ArtifactID: parts[1], Version: parts[2], Scope: defaultScope, Type: defaultType}
+		if len(parts) >= 4 && parts[3] != "" {
+			p.Scope = parts[3]
+		}
+		if len(parts) == 5 && parts[4] != "" {
+			p.Type = parts[4]
+		}
+		patches = append(patches, p)
+		return nil
+	})
+	return patches, err
+}
+
+// ParsePropertyList reads a list of properties, one per line, as
+// property=value (or pombump's property@value). Blank lines and lines
+// starting with # are skipped.
+func ParsePropertyList(r io.Reader) (map[string]string, error) {
+	properties := map[string]string{}
+	err := scanLines(r, func(n int, line string) error {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			name, value, ok = strings.Cut(line, "@")
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return fmt.Errorf("line %d: invalid property %q, must be property=value", n, line)
+		}
+		properties[name] = value
+		return nil
+	})
+	return properties, err
+}
+
+// scanLines calls f with the number and content of every line of r that is
+// not blank or a comment.
+func scanLines(r io.Reader, f func(n int, line string) error) error {
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := f(n, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed reading input: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCoordinateList(t *testing.T) {
+	testCases := []struct {
+		name    string
+		in      string
+		want    []Patch
+		wantErr bool
+	}{{
+		name: "coordinates",
+		in:   "# From the scanner\nio.netty:netty-handler:4.1.118.Final\n\n  org.json:json:20231013  \nch.qos.logback@logback-core@1.4.14@compile\n",
+		want: []Patch{
+			{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final", Scope: "import", Type: "jar"},
+			{GroupID: "org.json", ArtifactID: "json", Version: "20231013", Scope: "import", Type: "jar"},
+			{GroupID: "ch.qos.logback", ArtifactID: "logback-core", Version: "1.4.14", Scope: "compile", Type: "jar"},
+		},
+	}, {
+		name:    "missing version",
+		in:      "io.netty:netty-handler:4.1.118.Final\norg.json:json\n",
+		wantErr: true,
+	}, {
+		name:    "empty artifact",
+		in:      "org.json::20231013\n",
+		wantErr: true,
+	}, {
+		name:    "type before the version",
+		in:      "io.netty:netty-handler:jar:4.1.118.Final\n",
+		wantErr: true,
+	}, {
+		name:    "scope after the version",
+		in:      "org.json:json:20231013:test\n",
+		wantErr: true,
+	}, {
+		name:    "whitespace in the version",
+		in:      "org.json:json:20231013 test\n",
+		wantErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseCoordinateList(strings.NewReader(tc.in))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("%s: ParseCoordinateList() = %v, wantErr %v", tc.name, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("%s: ParseCoordinateList() (-want +got)\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestParsePropertyList(t *testing.T) {
+	got, err := ParsePropertyList(strings.NewReader("# Versions\nnetty.version=4.1.118.Final\njson.version@20231013\n"))
+	if err != nil {
+		t.Fatalf("ParsePropertyList() = %v", err)
+	}
+	want := map[string]string{"netty.version": "4.1.118.Final", "json.version": "20231013"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParsePropertyList() (-want +got)\n%s", diff)
+	}
+	if _, err := ParsePropertyList(strings.NewReader("netty.version\n")); err == nil {
+		t.Errorf("ParsePropertyList() with a missing value did not fail")
+	}
+}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// decodeFile reads the JSON or YAML file at path into dst. The format is
+// picked by the extension (.json, .yaml or .yml), or for other extensions by
+// sniffing the content: JSON documents start with { or [.
+func decodeFile(path string, dst any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed reading file: %w", err)
+	}
+	if isJSON(path, b) {
+		if err := json.Unmarshal(b, dst); err != nil {
+			return fmt.Errorf("failed to parse %s as JSON: %w", path, err)
+		}
+		return nil
+	}
+	if err := yaml.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("failed to parse %s as YAML: %w", path, err)
+	}
+	return nil
+}
+
+// documentSeparatorRe matches the --- lines that separate the documents of a
+// YAML stream.
+var documentSeparatorRe = regexp.MustCompile(`(?m)^---[ \t]*(#.*)?$`)
+
+// decodeDocuments is decodeFile for files that may hold several YAML
+// documents separated by ---, returning one T per document. A JSON file is a
+// single document.
+func decodeDocuments[T any](path string) ([]T, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading file: %w", err)
+	}
+	return decodeDocumentBytes[T](path, b)
+}
+
+// decodeDocumentBytes is decodeDocuments for content that is not a file of
+// its own, e.g. the comment block of a POM. The format is picked by the
+// extension of path, or by sniffing the content.
+func decodeDocumentBytes[T any](path string, b []byte) ([]T, error) {
+	if isJSON(path, b) {
+		var doc T
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s as JSON: %w", path, err)
+		}
+		return []T{doc}, nil
+	}
+
+	parts := documentSeparatorRe.Split(string(b), -1)
+	docs := make([]T, 0, len(parts))
+	for i, part := range parts {
+		var doc T
+		if err := yaml.Unmarshal([]byte(part), &doc); err != nil {
+			if len(parts) == 1 {
+				return nil, fmt.Errorf("failed to parse %s as YAML: %w", path, err)
+			}
+			return nil, fmt.Errorf("failed to parse document %d of %s as YAML: %w", i+1, path, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func isJSON(path string, b []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return true
+	case ".yaml", ".yml":
+		return false
+	}
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && (b[0] == '{' || b[0] == '[')
+}
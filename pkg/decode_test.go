@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeFileFormats(t *testing.T) {
+	testCases := []struct {
+		name  string
+		yaml  string
+		other string
+		parse func(path string) (any, error)
+	}{{
+		name:  "patches",
+		yaml:  "testdata/patches.yaml",
+		other: "testdata/patches.json",
+		parse: func(path string) (any, error) { return ParsePatches(path, "") },
+	}, {
+		name:  "properties",
+		yaml:  "testdata/properties.yaml",
+		other: "testdata/properties.json",
+		parse: func(path string) (any, error) { return ParseProperties(path, "") },
+	}, {
+		// No extension to go by, the content is sniffed.
+		name:  "constraints",
+		yaml:  "testdata/constraints.yaml",
+		other: "testdata/constraints.conf",
+		parse: func(path string) (any, error) { return ParseConstraints(path) },
+	}, {
+		name:  "batch manifest",
+		yaml:  "testdata/batch.yaml",
+		other: "testdata/batch.manifest",
+		parse: func(path string) (any, error) { return ParseBatchManifest(path) },
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := tc.parse(tc.yaml)
+			if err != nil {
+				t.Fatalf("%s: parsing %s = %v", tc.name, tc.yaml, err)
+			}
+			got, err := tc.parse(tc.other)
+			if err != nil {
+				t.Fatalf("%s: parsing %s = %v", tc.name, tc.other, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("%s: %s and %s differ (-yaml +other)\n%s", tc.name, tc.yaml, tc.other, diff)
+			}
+		})
+	}
+}
+
+func TestDecodeFileInvalidJSON(t *testing.T) {
+	var patchList PatchList
+	err := decodeFile("testdata/invalid-patches.json", &patchList)
+	if err == nil {
+		t.Fatal("decodeFile() with invalid JSON did not fail")
+	}
+	if want := "failed to parse testdata/invalid-patches.json as JSON"; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("decodeFile() = %v, want it to start with %q", err, want)
+	}
+}
@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// DriftKind is how a pinned version differs from the one a BOM manages.
+type DriftKind string
+
+const (
+	// DriftNewer is a version newer than the one the BOM manages.
+	DriftNewer DriftKind = "newer"
+	// DriftOlder is a version older than the one the BOM manages.
+	DriftOlder DriftKind = "older"
+	// DriftDifferent is a version that differs from the one the BOM
+	// manages, but can not be compared to it.
+	DriftDifferent DriftKind = "different"
+)
+
+// Drift is a dependency pinned to a different version than the one a BOM
+// manages.
+type Drift struct {
+	Kind       DriftKind `json:"kind"`
+	GroupID    string    `json:"groupId"`
+	ArtifactID string    `json:"artifactId"`
+	Version    string    `json:"version"`
+	BOMVersion string    `json:"bomVersion"`
+}
+
+// BOMDrift returns the dependencies of the project at path with an explicit
+// version that differs from the one in managed, e.g. as returned by
+// ManagedVersions for a BOM, sorted by groupId and artifactId. Versions are
+// resolved through the project's EffectiveProperties, ones that can not be
+// resolved are logged and not reported.
+func BOMDrift(ctx context.Context, path string, project *gopom.Project, managed map[string]string) []Drift {
+	log := clog.FromContext(ctx)
+	props := EffectiveProperties(ctx, path, project)
+
+	drifts := []Drift{}
+	seen := map[string]bool{}
+	for _, dep := range allDependencies(project) {
+		key := dep.GroupID + ":" + dep.ArtifactID
+		bomVersion, ok := managed[key]
+		if !ok || dep.Version == "" || isBOMImport(dep) {
+			continue
+		}
+		version, resolved := resolveVersion(dep.Version, props)
+		if !resolved {
+			log.Warnf("Can not resolve %s version %s, not comparing it to the BOM version %s", key, dep.Version, bomVersion)
+			continue
+		}
+		if version == bomVersion || seen[key+"@"+version] {
+			continue
+		}
+		seen[key+"@"+version] = true
+		d := Drift{Kind: DriftDifferent, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: version, BOMVersion: bomVersion}
+		if cmp, ok := compareVersions(version, bomVersion); ok {
+			switch {
+			case cmp > 0:
+				d.Kind = DriftNewer
+			case cmp < 0:
+				d.Kind = DriftOlder
+			default:
+				// Equivalent, e.g. 1.0 and 1.0.0.
+				continue
+			}
+		}
+		drifts = append(drifts, d)
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].GroupID != drifts[j].GroupID {
+			return drifts[i].GroupID < drifts[j].GroupID
+		}
+		return drifts[i].ArtifactID < drifts[j].ArtifactID
+	})
+	return drifts
+}
+
+// ImportsBOM returns true if the project imports the BOM in its
+// dependencyManagement.
+func ImportsBOM(project *gopom.Project, bom *gopom.Project) bool {
+	for _, dep := range managedDependencies(project) {
+		if isBOMImport(dep) && dep.GroupID == bom.GroupID && dep.ArtifactID == bom.ArtifactID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBOMDrift(t *testing.T) {
+	bom, err := gopom.Parse("testdata/bom.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := gopom.Parse("testdata/drift.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ImportsBOM(project, bom) {
+		t.Errorf("ImportsBOM() = false, want true")
+	}
+
+	want := []Drift{
+		{Kind: DriftNewer, GroupID: "io.netty", ArtifactID: "netty-codec-http", Version: "4.1.119.Final", BOMVersion: "4.1.118.Final"},
+		{Kind: DriftOlder, GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.94.Final", BOMVersion: "4.1.118.Final"},
+	}
+	got := BOMDrift(context.Background(), "testdata/drift.pom.xml", project, ManagedVersions(bom))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BOMDrift() (-want +got)\n%s", diff)
+	}
+
+	different := &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "[4.1,4.2)")}}
+	want = []Drift{{Kind: DriftDifferent, GroupID: "io.netty", ArtifactID: "netty-handler", Version: "[4.1,4.2)", BOMVersion: "4.1.118.Final"}}
+	got = BOMDrift(context.Background(), "testdata/missing/pom.xml", different, ManagedVersions(bom))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("BOMDrift() (-want +got)\n%s", diff)
+	}
+	if ImportsBOM(different, bom) {
+		t.Errorf("ImportsBOM() = true, want false")
+	}
+}
@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// embeddedRe matches a pombump comment block in a POM, e.g.
+//
+//	<!-- pombump:
+//	patches:
+//	  - groupId: io.netty
+//	    artifactId: netty-handler
+//	    version: 4.1.118.Final
+//	properties:
+//	  - property: slf4j.version
+//	    value: 2.0.16
+//	-->
+//
+// The body after the marker is YAML (or JSON) with the patches of a patch
+// file and the properties of a properties file, and like those it can be a
+// stream of several documents.
+var embeddedRe = regexp.MustCompile(`(?s)<!--\s*pombump:(.*?)-->`)
+
+// embeddedList is the format of the body of a pombump comment block.
+type embeddedList struct {
+	Patches    []Patch         `json:"patches"`
+	Properties []PropertyPatch `json:"properties"`
+}
+
+// Embedded are the patches and properties a POM declares for itself in a
+// pombump comment block.
+type Embedded struct {
+	Patches    []Patch
+	Properties map[string]string
+	// Comment is the whole comment block, so that it can be put back into
+	// the patched POM, which loses its comments.
+	Comment string
+}
+
+// ParseEmbedded reads the pombump comment block of the POM at path. Returns
+// nil if there is none, and an error if there is more than one.
+func ParseEmbedded(path string) (*Embedded, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading file: %w", err)
+	}
+	matches := embeddedRe.FindAllSubmatch(b, -1)
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("%s has %d pombump comment blocks, only one is supported", path, len(matches))
+	}
+
+	// Decoded like a patch file, the POM's extension is not JSON or YAML so
+	// the format is sniffed.
+	docs, err := decodeDocumentBytes[embeddedList](path, matches[0][1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the pombump comment block: %w", err)
+	}
+	embedded := &Embedded{Properties: map[string]string{}, Comment: string(matches[0][0])}
+	// Later documents win, as in a properties file.
+	for _, doc := range docs {
+		embedded.Patches = append(embedded.Patches, doc.Patches...)
+		for _, p := range doc.Properties {
+			embedded.Properties[p.Property] = p.Value
+		}
+	}
+	if err := defaultPatches(embedded.Patches); err != nil {
+		return nil, err
+	}
+	return embedded, nil
+}
+
+// projectStartRe matches the start tag of the project element.
+var projectStartRe = regexp.MustCompile(`<project(\s[^>]*)?>`)
+
+// InsertComment puts comment back into a marshaled POM, as the first thing
+// in the project element. The POM is returned as is if it has no project
+// element.
+func InsertComment(pom []byte, comment string) []byte {
+	loc := projectStartRe.FindIndex(pom)
+	if loc == nil {
+		return pom
+	}
+	out := make([]byte, 0, len(pom)+len(comment)+6)
+	out = append(out, pom[:loc[1]]...)
+	out = append(out, "\n    "...)
+	out = append(out, comment...)
+	return append(out, pom[loc[1]:]...)
+}
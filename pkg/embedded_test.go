@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseEmbedded(t *testing.T) {
+	got, err := ParseEmbedded("testdata/embedded.pom.xml")
+	if err != nil {
+		t.Fatalf("ParseEmbedded() = %v", err)
+	}
+	want := &Embedded{
+		Patches:    []Patch{{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final", Scope: defaultScope, Type: defaultType, Reason: "CVE-2025-24970"}},
+		Properties: map[string]string{"slf4j.version": "2.0.16"},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Embedded{}, "Comment")); diff != "" {
+		t.Errorf("ParseEmbedded() (-want +got)\n%s", diff)
+	}
+	if !strings.HasPrefix(got.Comment, "<!-- pombump:") || !strings.HasSuffix(got.Comment, "-->") {
+		t.Errorf("ParseEmbedded() Comment = %q, want the whole comment block", got.Comment)
+	}
+
+	none, err := ParseEmbedded("testdata/drift.pom.xml")
+	if err != nil || none != nil {
+		t.Errorf("ParseEmbedded() without a block = %v, %v, want nil, nil", none, err)
+	}
+
+	twice := filepath.Join(t.TempDir(), "pom.xml")
+	if err := os.WriteFile(twice, []byte("<project><!-- pombump: {} --><!-- pombump: {} --></project>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseEmbedded(twice); err == nil {
+		t.Errorf("ParseEmbedded() with two blocks did not fail")
+	}
+}
+
+func TestParseEmbeddedFormats(t *testing.T) {
+	want := &Embedded{
+		Patches:    []Patch{{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final", Scope: defaultScope, Type: defaultType}},
+		Properties: map[string]string{"slf4j.version": "2.0.16"},
+	}
+	testCases := []struct {
+		name string
+		body string
+	}{{
+		name: "json",
+		body: `{"patches": [{"groupId": "io.netty", "artifactId": "netty-handler", "version": "4.1.118.Final"}], "properties": [{"property": "slf4j.version", "value": "2.0.16"}]}`,
+	}, {
+		name: "several documents",
+		body: "\npatches:\n  - groupId: io.netty\n    artifactId: netty-handler\n    version: 4.1.118.Final\n---\nproperties:\n  - property: slf4j.version\n    value: 2.0.16\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pom.xml")
+			if err := os.WriteFile(path, []byte("<project><!-- pombump: "+tc.body+" --></project>"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseEmbedded(path)
+			if err != nil {
+				t.Fatalf("ParseEmbedded() = %v", err)
+			}
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Embedded{}, "Comment")); diff != "" {
+				t.Errorf("ParseEmbedded() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInsertComment(t *testing.T) {
+	testCases := []struct {
+		name string
+		pom  string
+		want string
+	}{{
+		name: "attributes",
+		pom:  "<?xml version=\"1.0\"?>\n<project xmlns=\"x\">\n    <modelVersion>4.0.0</modelVersion>\n</project>",
+		want: "<?xml version=\"1.0\"?>\n<project xmlns=\"x\">\n    <!-- pombump: {} -->\n    <modelVersion>4.0.0</modelVersion>\n</project>",
+	}, {
+		name: "no attributes",
+		pom:  "<project>\n</project>",
+		want: "<project>\n    <!-- pombump: {} -->\n</project>",
+	}, {
+		name: "no project",
+		pom:  "<projects></projects>",
+		want: "<projects></projects>",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, string(InsertComment([]byte(tc.pom), "<!-- pombump: {} -->"))); diff != "" {
+				t.Errorf("InsertComment() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
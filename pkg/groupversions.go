@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// GroupVersionList is the format of a group versions file: the version to set
+// on every dependency under a groupId prefix.
+type GroupVersionList struct {
+	GroupVersions []GroupVersion `json:"groupVersions" yaml:"groupVersions"`
+}
+
+// GroupVersion is the version for the dependencies whose groupId is Prefix,
+// or starts with Prefix followed by a dot.
+type GroupVersion struct {
+	Prefix  string `json:"prefix" yaml:"prefix"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// ParseGroupVersions reads a group versions file.
+func ParseGroupVersions(groupVersionsFile string) ([]GroupVersion, error) {
+	var groupVersionList GroupVersionList
+	if err := decodeFile(groupVersionsFile, &groupVersionList); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, g := range groupVersionList.GroupVersions {
+		if g.Prefix == "" || g.Version == "" {
+			return nil, fmt.Errorf("invalid group version %s:%s, prefix and version are required", g.Prefix, g.Version)
+		}
+		if seen[g.Prefix] {
+			return nil, fmt.Errorf("group prefix %s is given more than once", g.Prefix)
+		}
+		seen[g.Prefix] = true
+	}
+	return groupVersionList.GroupVersions, nil
+}
+
+// matchesGroupPrefix returns true if groupID is prefix, or under it.
+func matchesGroupPrefix(groupID, prefix string) bool {
+	return groupID == prefix || strings.HasPrefix(groupID, prefix+".")
+}
+
+// GroupVersionPatches returns the patches that set the dependencies already
+// in the project to the version of the longest group prefix they match.
+// Dependencies that are not in the project are never added, and versions
+// that come from properties are left alone, since other dependencies may
+// share the property.
+func GroupVersionPatches(ctx context.Context, project *gopom.Project, groups []GroupVersion) []Patch {
+	log := clog.FromContext(ctx)
+
+	// Longest prefix first, so the first match wins.
+	sorted := append([]GroupVersion{}, groups...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Prefix) > len(sorted[j].Prefix) })
+
+	patches := []Patch{}
+	seen := map[string]bool{}
+	for _, dep := range allDependencies(project) {
+		key := dep.GroupID + ":" + dep.ArtifactID
+		if dep.Version == "" || seen[key] {
+			continue
+		}
+		var matches []GroupVersion
+		for _, g := range sorted {
+			if matchesGroupPrefix(dep.GroupID, g.Prefix) {
+				matches = append(matches, g)
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		seen[key] = true
+		if len(matches) > 1 {
+			prefixes := make([]string, 0, len(matches))
+			for _, m := range matches {
+				prefixes = append(prefixes, m.Prefix)
+			}
+			log.Warnf("%s matches the group prefixes %s, using the longest one", key, strings.Join(prefixes, ", "))
+		}
+		g := matches[0]
+		if strings.Contains(dep.Version, "${") {
+			log.Warnf("Not setting %s to %s for group prefix %s, its version %s comes from a property", key, g.Version, g.Prefix, dep.Version)
+			continue
+		}
+		if dep.Version == g.Version {
+			continue
+		}
+		log.Infof("Setting %s from %s to %s for group prefix %s", key, dep.Version, g.Version, g.Prefix)
+		patches = append(patches, Patch{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: g.Version, Scope: dep.Scope, Type: dep.Type})
+	}
+	return patches
+}
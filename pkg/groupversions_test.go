@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseGroupVersions(t *testing.T) {
+	got, err := ParseGroupVersions("testdata/group-versions.yaml")
+	if err != nil {
+		t.Fatalf("ParseGroupVersions() = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("ParseGroupVersions() got %d group versions, want 3", len(got))
+	}
+	if _, err := ParseGroupVersions("testdata/missing"); err == nil {
+		t.Errorf("ParseGroupVersions() with a missing file did not fail")
+	}
+}
+
+func TestMatchesGroupPrefix(t *testing.T) {
+	testCases := []struct {
+		groupID string
+		prefix  string
+		want    bool
+	}{
+		{"io.netty", "io.netty", true},
+		{"io.netty.incubator", "io.netty", true},
+		{"io.nettyx", "io.netty", false},
+		{"io", "io.netty", false},
+	}
+	for _, tc := range testCases {
+		if got := matchesGroupPrefix(tc.groupID, tc.prefix); got != tc.want {
+			t.Errorf("matchesGroupPrefix(%q, %q) = %v, want %v", tc.groupID, tc.prefix, got, tc.want)
+		}
+	}
+}
+
+func TestGroupVersionPatches(t *testing.T) {
+	groups, err := ParseGroupVersions("testdata/group-versions.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedPom, err := gopom.Parse("testdata/group-versions.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Patch{
+		{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "4.1.118.Final"},
+		{GroupID: "io.netty.incubator", ArtifactID: "netty-incubator-codec-quic", Version: "0.0.26.Final"},
+	}
+	if diff := cmp.Diff(want, GroupVersionPatches(context.Background(), parsedPom, groups)); diff != "" {
+		t.Errorf("GroupVersionPatches() (-want +got)\n%s", diff)
+	}
+}
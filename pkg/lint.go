@@ -0,0 +1,350 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+// Severity of a lint Finding.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var severityRank = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity parses a severity name.
+func ParseSeverity(s string) (Severity, error) {
+	if _, ok := severityRank[Severity(s)]; !ok {
+		return "", fmt.Errorf("invalid severity %q, must be one of: info, warning, error", s)
+	}
+	return Severity(s), nil
+}
+
+// AtLeast returns true if s is at least as severe as other.
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRank[s] >= severityRank[other]
+}
+
+// Code is the stable identifier of a LintCheck. Unlike the check names and
+// messages, codes never change, so they can be used to refer to findings,
+// e.g. to disable them.
+type Code string
+
+const (
+	CodeDuplicateDependency          Code = "PB001"
+	CodeDuplicateProperty            Code = "PB002"
+	CodeVersionlessManagedDependency Code = "PB003"
+	CodeMisplacedImport              Code = "PB004"
+	CodeSnapshotVersion              Code = "PB005"
+	CodeTransposedCoordinates        Code = "PB006"
+	CodeUnusedProperty               Code = "PB007"
+	CodePropertyCycle                Code = "PB008"
+	CodeMetaVersion                  Code = "PB009"
+	CodeManagedAggregator            Code = "PB010"
+	CodeUnmanagedDependency          Code = "PB011"
+	CodeModelVersion                 Code = "PB012"
+)
+
+// Finding is a single POM hygiene issue found by a LintCheck.
+type Finding struct {
+	Code     Code     `json:"code"`
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// LintCheck is a named check that inspects a project for one kind of issue.
+type LintCheck struct {
+	Code Code
+	Name string
+	Run  func(project *gopom.Project) []Finding
+}
+
+// LintChecks are all the available checks, in the order they are reported.
+var LintChecks = []LintCheck{
+	{Code: CodeDuplicateDependency, Name: "duplicate-dependency", Run: checkDuplicateDependencies},
+	{Code: CodeDuplicateProperty, Name: "duplicate-property", Run: checkDuplicateProperties},
+	{Code: CodeVersionlessManagedDependency, Name: "versionless-managed-dependency", Run: checkVersionlessManagedDependencies},
+	{Code: CodeMisplacedImport, Name: "misplaced-import", Run: checkMisplacedImports},
+	{Code: CodeSnapshotVersion, Name: "snapshot-version", Run: checkSnapshotVersions},
+	{Code: CodeTransposedCoordinates, Name: "transposed-coordinates", Run: checkTransposedCoordinates},
+	{Code: CodeUnusedProperty, Name: "unused-property", Run: checkUnusedProperties},
+	{Code: CodePropertyCycle, Name: "property-cycle", Run: checkPropertyCycles},
+	{Code: CodeMetaVersion, Name: "meta-version", Run: checkMetaVersions},
+	{Code: CodeManagedAggregator, Name: "managed-aggregator", Run: checkManagedAggregators},
+	{Code: CodeUnmanagedDependency, Name: "unmanaged-dependency", Run: checkUnmanagedDependencies},
+	{Code: CodeModelVersion, Name: "model-version", Run: checkModelVersion},
+}
+
+// Lint runs the LintChecks against the project, except the ones whose code
+// is in disabled.
+func Lint(project *gopom.Project, disabled ...Code) []Finding {
+	skip := map[Code]bool{}
+	for _, c := range disabled {
+		skip[c] = true
+	}
+	findings := []Finding{}
+	for _, check := range LintChecks {
+		if skip[check.Code] {
+			continue
+		}
+		for _, f := range check.Run(project) {
+			f.Code = check.Code
+			f.Check = check.Name
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// ParseCodes parses a comma-separated list of check codes, e.g. PB001,PB003.
+func ParseCodes(s string) ([]Code, error) {
+	known := map[Code]bool{}
+	for _, check := range LintChecks {
+		known[check.Code] = true
+	}
+	var codes []Code
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !known[Code(strings.ToUpper(c))] {
+			return nil, fmt.Errorf("unknown check code %q", c)
+		}
+		codes = append(codes, Code(strings.ToUpper(c)))
+	}
+	return codes, nil
+}
+
+// dependencyKey identifies a dependency the way Maven does when looking for
+// duplicates, which includes the type and classifier.
+func dependencyKey(dep gopom.Dependency) string {
+	key := dep.GroupID + ":" + dep.ArtifactID
+	if dep.Type != "" && dep.Type != defaultType {
+		key += ":" + dep.Type
+	}
+	if dep.Classifier != "" {
+		key += ":" + dep.Classifier
+	}
+	return key
+}
+
+// modelVersion is the only model version Maven supports.
+const modelVersion = "4.0.0"
+
+func checkModelVersion(project *gopom.Project) []Finding {
+	switch project.ModelVersion {
+	case modelVersion:
+		return nil
+	case "":
+		return []Finding{{Severity: SeverityWarning, Message: fmt.Sprintf("the project has no modelVersion, it must be %s", modelVersion)}}
+	default:
+		return []Finding{{Severity: SeverityWarning, Message: fmt.Sprintf("the project has modelVersion %s, it must be %s", project.ModelVersion, modelVersion)}}
+	}
+}
+
+func checkDuplicateDependencies(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, section := range []struct {
+		name string
+		deps []gopom.Dependency
+	}{
+		{"dependencies", dependencies(project.Dependencies)},
+		{"dependencyManagement", managedDependencies(project)},
+	} {
+		counts := map[string]int{}
+		for _, dep := range section.deps {
+			key := dependencyKey(dep)
+			counts[key]++
+			if counts[key] == 2 {
+				findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("%s is declared more than once in %s", key, section.name)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkDuplicateProperties(project *gopom.Project) []Finding {
+	if project.Properties == nil {
+		return nil
+	}
+	var findings []Finding
+	counts := map[string]int{}
+	for _, name := range project.Properties.Order {
+		counts[name]++
+		if counts[name] == 2 {
+			findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("property %s is defined more than once, only the last value is used", name)})
+		}
+	}
+	return findings
+}
+
+func checkVersionlessManagedDependencies(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range managedDependencies(project) {
+		if dep.Version == "" {
+			findings = append(findings, Finding{Severity: SeverityError, Message: fmt.Sprintf("%s:%s in dependencyManagement has no version", dep.GroupID, dep.ArtifactID)})
+		}
+	}
+	return findings
+}
+
+// checkUnmanagedDependencies reports dependencies without a version that
+// nothing can manage: they are not in dependencyManagement, and there is no
+// parent or imported BOM that could manage them either.
+func checkUnmanagedDependencies(project *gopom.Project) []Finding {
+	if project.Parent != nil {
+		return nil
+	}
+	managed := map[string]bool{}
+	for _, dep := range managedDependencies(project) {
+		if isBOMImport(dep) {
+			return nil
+		}
+		managed[dep.GroupID+":"+dep.ArtifactID] = true
+	}
+	var findings []Finding
+	for _, dep := range dependencies(project.Dependencies) {
+		if dep.Version == "" && !managed[dep.GroupID+":"+dep.ArtifactID] {
+			findings = append(findings, Finding{Severity: SeverityError, Message: fmt.Sprintf("%s:%s has no version, and there is no dependencyManagement entry, parent or BOM that could manage it", dep.GroupID, dep.ArtifactID)})
+		}
+	}
+	return findings
+}
+
+func checkMisplacedImports(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range dependencies(project.Dependencies) {
+		if dep.Scope == "import" {
+			findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("%s:%s has scope import in dependencies, it is only supported in dependencyManagement", dep.GroupID, dep.ArtifactID)})
+		}
+	}
+	return findings
+}
+
+func checkManagedAggregators(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range managedDependencies(project) {
+		if isAggregator(dep) {
+			findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("%s:%s in dependencyManagement has type pom without scope import, it manages the version of an aggregator and is not imported as a BOM", dep.GroupID, dep.ArtifactID)})
+		}
+	}
+	return findings
+}
+
+func checkSnapshotVersions(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range allDependencies(project) {
+		if strings.HasSuffix(dep.Version, "-SNAPSHOT") {
+			findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("%s:%s uses snapshot version %s", dep.GroupID, dep.ArtifactID, dep.Version)})
+		}
+	}
+	if project.Properties != nil {
+		for _, name := range project.Properties.Order {
+			if v := project.Properties.Entries[name]; strings.HasSuffix(v, "-SNAPSHOT") {
+				findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("property %s uses snapshot version %s", name, v)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkMetaVersions(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range allDependencies(project) {
+		if isMetaVersion(dep.Version) {
+			findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("%s:%s uses version %s, which is deprecated and not reproducible", dep.GroupID, dep.ArtifactID, dep.Version)})
+		}
+	}
+	if project.Properties != nil {
+		// Only properties that are dependency versions, others, e.g. a
+		// build.type, may well be RELEASE.
+		versions := dependencyProperties(project, nil)
+		for _, name := range project.Properties.Order {
+			if v := project.Properties.Entries[name]; versions[name] && isMetaVersion(v) {
+				findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf("property %s uses version %s, which is deprecated and not reproducible", name, v)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkTransposedCoordinates(project *gopom.Project) []Finding {
+	var findings []Finding
+	for _, dep := range allDependencies(project) {
+		if looksTransposed(Patch{GroupID: dep.GroupID, ArtifactID: dep.ArtifactID}) {
+			findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("%s:%s looks like it has groupId and artifactId swapped", dep.GroupID, dep.ArtifactID)})
+		}
+	}
+	return findings
+}
+
+// checkUnusedProperties reports properties that are not referenced anywhere
+// in the POM. They may still be used by child modules or implicitly by
+// plugins (e.g. project.build.sourceEncoding), so this is only informational.
+func checkUnusedProperties(project *gopom.Project) []Finding {
+	if project.Properties == nil || len(project.Properties.Entries) == 0 {
+		return nil
+	}
+	referenced, err := referencedPropertyNames(project)
+	if err != nil {
+		return []Finding{{Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var findings []Finding
+	seen := map[string]bool{}
+	for _, name := range project.Properties.Order {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !referenced[name] {
+			findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("property %s is not referenced in this POM", name)})
+		}
+	}
+	return findings
+}
+
+// commentRe matches an XML comment, e.g. in raw plugin configuration.
+var commentRe = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// referencedPropertyNames returns the properties referenced anywhere in the
+// project, including plugin configuration and other properties, but not in
+// comments.
+func referencedPropertyNames(project *gopom.Project) (map[string]bool, error) {
+	// Marshal moves the xsi attributes around, so use a copy to leave the
+	// project alone.
+	cp := *project
+	out, err := cp.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the project to look for property references: %w", err)
+	}
+	referenced := map[string]bool{}
+	for _, m := range propertyRefRe.FindAllSubmatch(commentRe.ReplaceAll(out, nil), -1) {
+		referenced[string(m[1])] = true
+	}
+	return referenced, nil
+}
+
+func checkPropertyCycles(project *gopom.Project) []Finding {
+	if project.Properties == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, cycle := range propertyCycles(project.Properties.Entries) {
+		findings = append(findings, Finding{Severity: SeverityError, Message: fmt.Sprintf("properties %s reference each other in a cycle and can not be resolved", strings.Join(append(cycle, cycle[0]), " -> "))})
+	}
+	return findings
+}
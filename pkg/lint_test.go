@@ -0,0 +1,202 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	parsedPom, err := gopom.Parse("testdata/lint.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Finding{
+		{Code: CodeDuplicateDependency, Check: "duplicate-dependency", Severity: SeverityWarning, Message: "com.fasterxml.jackson.core:jackson-databind is declared more than once in dependencies"},
+		{Code: CodeDuplicateProperty, Check: "duplicate-property", Severity: SeverityWarning, Message: "property jackson.version is defined more than once, only the last value is used"},
+		{Code: CodeVersionlessManagedDependency, Check: "versionless-managed-dependency", Severity: SeverityError, Message: "org.slf4j:slf4j-api in dependencyManagement has no version"},
+		{Code: CodeMisplacedImport, Check: "misplaced-import", Severity: SeverityWarning, Message: "com.fasterxml.jackson:jackson-bom has scope import in dependencies, it is only supported in dependencyManagement"},
+		{Code: CodeSnapshotVersion, Check: "snapshot-version", Severity: SeverityWarning, Message: "dev.chainguard:snapshot uses snapshot version 1.0.0-SNAPSHOT"},
+		{Code: CodeSnapshotVersion, Check: "snapshot-version", Severity: SeverityWarning, Message: "property snapshot.version uses snapshot version 2.0.0-SNAPSHOT"},
+		{Code: CodeTransposedCoordinates, Check: "transposed-coordinates", Severity: SeverityInfo, Message: "logback-core:ch.qos.logback looks like it has groupId and artifactId swapped"},
+		{Code: CodeUnusedProperty, Check: "unused-property", Severity: SeverityInfo, Message: "property unused.version is not referenced in this POM"},
+	}
+	if diff := cmp.Diff(want, Lint(parsedPom)); diff != "" {
+		t.Errorf("Lint() (-want +got)\n%s", diff)
+	}
+}
+
+func TestLintDisabled(t *testing.T) {
+	parsedPom, err := gopom.Parse("testdata/lint.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled, err := ParseCodes("PB001, pb005,PB006,PB007,PB002,PB004")
+	if err != nil {
+		t.Fatalf("ParseCodes() = %v", err)
+	}
+	want := []Finding{
+		{Code: CodeVersionlessManagedDependency, Check: "versionless-managed-dependency", Severity: SeverityError, Message: "org.slf4j:slf4j-api in dependencyManagement has no version"},
+	}
+	if diff := cmp.Diff(want, Lint(parsedPom, disabled...)); diff != "" {
+		t.Errorf("Lint() (-want +got)\n%s", diff)
+	}
+	if _, err := ParseCodes("PB001,PB999"); err == nil {
+		t.Errorf("ParseCodes() with an unknown code did not fail")
+	}
+}
+
+func TestLintPropertyCycle(t *testing.T) {
+	project := &gopom.Project{
+		ModelVersion: "4.0.0",
+		Properties:   &gopom.Properties{Entries: map[string]string{"a.version": "${b.version}", "b.version": "${a.version}"}, Order: []string{"a.version", "b.version"}},
+		Dependencies: &[]gopom.Dependency{{GroupID: "org.a", ArtifactID: "a", Version: "${a.version}"}},
+	}
+	want := []Finding{
+		{Code: CodePropertyCycle, Check: "property-cycle", Severity: SeverityError, Message: "properties a.version -> b.version -> a.version reference each other in a cycle and can not be resolved"},
+	}
+	if diff := cmp.Diff(want, Lint(project)); diff != "" {
+		t.Errorf("Lint() (-want +got)\n%s", diff)
+	}
+}
+
+func TestLintMetaVersion(t *testing.T) {
+	project := &gopom.Project{
+		ModelVersion: "4.0.0",
+		Description:  "A ${build.type} build",
+		Properties:   &gopom.Properties{Entries: map[string]string{"a.version": "RELEASE", "build.type": "RELEASE"}, Order: []string{"a.version", "build.type"}},
+		Dependencies: &[]gopom.Dependency{{GroupID: "org.a", ArtifactID: "a", Version: "${a.version}"}, {GroupID: "org.b", ArtifactID: "b", Version: "LATEST"}},
+	}
+	want := []Finding{
+		{Code: CodeMetaVersion, Check: "meta-version", Severity: SeverityWarning, Message: "org.b:b uses version LATEST, which is deprecated and not reproducible"},
+		{Code: CodeMetaVersion, Check: "meta-version", Severity: SeverityWarning, Message: "property a.version uses version RELEASE, which is deprecated and not reproducible"},
+	}
+	if diff := cmp.Diff(want, Lint(project)); diff != "" {
+		t.Errorf("Lint() (-want +got)\n%s", diff)
+	}
+}
+
+func TestLintManagedAggregator(t *testing.T) {
+	parsedPom, err := gopom.Parse("testdata/aggregator.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The BOM import and the aggregator in dependencies are fine.
+	want := []Finding{
+		{Code: CodeManagedAggregator, Check: "managed-aggregator", Severity: SeverityInfo, Message: "org.apache.tika:tika-parsers in dependencyManagement has type pom without scope import, it manages the version of an aggregator and is not imported as a BOM"},
+	}
+	if diff := cmp.Diff(want, Lint(parsedPom)); diff != "" {
+		t.Errorf("Lint() (-want +got)\n%s", diff)
+	}
+}
+
+func TestLintUnmanagedDependency(t *testing.T) {
+	deps := &[]gopom.Dependency{{GroupID: "org.a", ArtifactID: "a"}, {GroupID: "org.b", ArtifactID: "b"}}
+	testCases := []struct {
+		name    string
+		project *gopom.Project
+		want    []Finding
+	}{{
+		name: "unmanaged",
+		project: &gopom.Project{
+			ModelVersion:         "4.0.0",
+			Dependencies:         deps,
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{{GroupID: "org.a", ArtifactID: "a", Version: "1.0.0"}}},
+		},
+		want: []Finding{
+			{Code: CodeUnmanagedDependency, Check: "unmanaged-dependency", Severity: SeverityError, Message: "org.b:b has no version, and there is no dependencyManagement entry, parent or BOM that could manage it"},
+		},
+	}, {
+		name:    "parent may manage it",
+		project: &gopom.Project{ModelVersion: "4.0.0", Parent: &gopom.Parent{GroupID: "org.p", ArtifactID: "p", Version: "1.0.0"}, Dependencies: deps},
+		want:    []Finding{},
+	}, {
+		name: "BOM may manage it",
+		project: &gopom.Project{
+			ModelVersion:         "4.0.0",
+			Dependencies:         deps,
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{{GroupID: "org.bom", ArtifactID: "bom", Version: "1.0.0", Type: "pom", Scope: "import"}}},
+		},
+		want: []Finding{},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, Lint(tc.project)); diff != "" {
+				t.Errorf("Lint() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintModelVersion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		modelVersion string
+		want         []Finding
+	}{{
+		name:         "supported",
+		modelVersion: "4.0.0",
+		want:         []Finding{},
+	}, {
+		name: "missing",
+		want: []Finding{
+			{Code: CodeModelVersion, Check: "model-version", Severity: SeverityWarning, Message: "the project has no modelVersion, it must be 4.0.0"},
+		},
+	}, {
+		name:         "unsupported",
+		modelVersion: "4.1.0",
+		want: []Finding{
+			{Code: CodeModelVersion, Check: "model-version", Severity: SeverityWarning, Message: "the project has modelVersion 4.1.0, it must be 4.0.0"},
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, Lint(&gopom.Project{ModelVersion: tc.modelVersion})); diff != "" {
+				t.Errorf("Lint() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	project := &gopom.Project{
+		ModelVersion: "4.0.0",
+		Properties:   &gopom.Properties{Entries: map[string]string{"a.version": "1.0.0"}, Order: []string{"a.version"}},
+		Dependencies: &[]gopom.Dependency{{GroupID: "org.a", ArtifactID: "a", Version: "${a.version}"}},
+	}
+	if got := Lint(project); len(got) != 0 {
+		t.Errorf("Lint() = %+v, want no findings", got)
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	if !SeverityError.AtLeast(SeverityWarning) {
+		t.Errorf("error should be at least warning")
+	}
+	if SeverityInfo.AtLeast(SeverityWarning) {
+		t.Errorf("info should not be at least warning")
+	}
+	if !SeverityWarning.AtLeast(SeverityWarning) {
+		t.Errorf("warning should be at least warning")
+	}
+}
+
+func TestReferencedPropertyNames(t *testing.T) {
+	project := &gopom.Project{
+		Properties:   &gopom.Properties{Entries: map[string]string{"netty.version": "${netty.major}.118.Final", "netty.major": "4.1"}, Order: []string{"netty.version", "netty.major"}},
+		Dependencies: &[]gopom.Dependency{{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "${netty.version}"}},
+		Build: &gopom.Build{BuildBase: gopom.BuildBase{Plugins: &[]gopom.Plugin{{
+			ArtifactID:    "maven-compiler-plugin",
+			Configuration: &gopom.Configuration{RawConfiguration: "<release>${java.version}</release><!-- was ${old.java.version} -->"},
+		}}}},
+	}
+	got, err := referencedPropertyNames(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"netty.version": true, "netty.major": true, "java.version": true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("referencedPropertyNames() (-want +got)\n%s", diff)
+	}
+}
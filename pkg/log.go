@@ -0,0 +1,16 @@
+package pkg
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// WithLogger returns a context that carries logger. All the functions in this
+// package log through the logger of the context they are given (falling back
+// to slog.Default()), so this scopes their logging to a single call without
+// touching the global default.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return clog.WithLogger(ctx, clog.NewLogger(logger))
+}
@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+
+	project := &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a", "b", "1.0.0")}}
+	if _, err := PatchProject(ctx, project, []Patch{{GroupID: "a", ArtifactID: "b", Version: "1.0.1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Patching a.b from 1.0.0 to 1.0.1") {
+		t.Errorf("logs did not go to the context logger, got: %s", buf.String())
+	}
+}
@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/ghodss/yaml"
+)
+
+// WritePatchFile writes patches to path in the --patch-file format. If the
+// file already exists, its patches are kept and updated: a patch for the
+// same groupId and artifactId replaces the existing one, others are
+// added. With overwrite, the existing file is replaced instead. Patches are
+// sorted by groupId and artifactId so that the file is stable across runs.
+// The file is written with WriteFile and mode.
+func WritePatchFile(path string, patches []Patch, mode fs.FileMode, overwrite bool) error {
+	var existing []Patch
+	if !overwrite {
+		var err error
+		existing, err = readExisting(path, func() ([]Patch, error) { return ParsePatches(path, "") })
+		if err != nil {
+			return err
+		}
+	}
+
+	index := make(map[string]int, len(existing))
+	for i, p := range existing {
+		index[p.GroupID+":"+p.ArtifactID] = i
+	}
+	final := existing
+	for _, p := range patches {
+		if i, ok := index[p.GroupID+":"+p.ArtifactID]; ok {
+			final[i] = p
+			continue
+		}
+		index[p.GroupID+":"+p.ArtifactID] = len(final)
+		final = append(final, p)
+	}
+
+	out, err := MarshalPatchFile(final)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, out, mode)
+}
+
+// MarshalPatchFile marshals patches in the --patch-file format, sorted by
+// groupId and artifactId.
+func MarshalPatchFile(patches []Patch) ([]byte, error) {
+	sorted := append([]Patch{}, patches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].GroupID != sorted[j].GroupID {
+			return sorted[i].GroupID < sorted[j].GroupID
+		}
+		return sorted[i].ArtifactID < sorted[j].ArtifactID
+	})
+	out, err := yaml.Marshal(PatchList{Patches: sorted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal patches: %w", err)
+	}
+	return out, nil
+}
+
+// WritePropertiesFile writes properties to path in the --properties-file
+// format. If the file already exists, its properties are kept and the ones
+// given here are added or overwritten. With overwrite, the existing file is
+// replaced instead. The file is written with WriteFile and mode.
+func WritePropertiesFile(path string, properties map[string]string, mode fs.FileMode, overwrite bool) error {
+	var final map[string]string
+	if !overwrite {
+		var err error
+		final, err = readExisting(path, func() (map[string]string, error) { return ParseProperties(path, "") })
+		if err != nil {
+			return err
+		}
+	}
+	if final == nil {
+		final = map[string]string{}
+	}
+	for k, v := range properties {
+		final[k] = v
+	}
+
+	out, err := MarshalPropertiesFile(final)
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, out, mode)
+}
+
+// MarshalPropertiesFile marshals properties in the --properties-file format,
+// sorted by name.
+func MarshalPropertiesFile(properties map[string]string) ([]byte, error) {
+	names := make([]string, 0, len(properties))
+	for k := range properties {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	propertyList := PropertyList{Properties: make([]PropertyPatch, 0, len(names))}
+	for _, k := range names {
+		propertyList.Properties = append(propertyList.Properties, PropertyPatch{Property: k, Value: properties[k]})
+	}
+	out, err := yaml.Marshal(propertyList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal properties: %w", err)
+	}
+	return out, nil
+}
+
+// WriteFile writes data to path. With a mode, the file gets exactly those
+// permissions, also when it already exists or the umask would clear some of
+// them. A zero mode is os.WriteFile's behavior: a new file is created with
+// 0644 less the umask, and an existing file keeps its permissions.
+func WriteFile(path string, data []byte, mode fs.FileMode) error {
+	if mode == 0 {
+		return os.WriteFile(path, data, 0o644)
+	}
+	if err := os.WriteFile(path, data, mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
+
+// readExisting reads the existing file at path with read, returning the zero
+// value if there is no such file yet.
+func readExisting[T any](path string, read func() (T, error)) (T, error) {
+	var zero T
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return zero, nil
+	}
+	existing, err := read()
+	if err != nil {
+		return zero, fmt.Errorf("failed to read existing %s: %w", path, err)
+	}
+	return existing, nil
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWritePatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patches.yaml")
+
+	// Out of order, the file is sorted by coordinate.
+	first := []Patch{
+		{GroupID: "g2", ArtifactID: "a2", Version: "2.0.0", Scope: "compile", Type: "pom"},
+		{GroupID: "g1", ArtifactID: "a1", Version: "1.0.0", Scope: "import", Type: "jar"},
+	}
+	if err := WritePatchFile(path, first, 0o644, false); err != nil {
+		t.Fatalf("WritePatchFile() = %v", err)
+	}
+	got, err := ParsePatches(path, "")
+	if err != nil {
+		t.Fatalf("ParsePatches() = %v", err)
+	}
+	if diff := cmp.Diff([]Patch{first[1], first[0]}, got); diff != "" {
+		t.Errorf("first write (-want +got)\n%s", diff)
+	}
+
+	// Writing again updates the matching patch and adds the new ones.
+	second := []Patch{
+		{GroupID: "g3", ArtifactID: "a3", Version: "3.0.0", Scope: "import", Type: "jar"},
+		{GroupID: "g1", ArtifactID: "a1", Version: "1.0.1", Scope: "import", Type: "jar"},
+		{GroupID: "g1", ArtifactID: "a0", Version: "0.0.1", Scope: "import", Type: "jar"},
+	}
+	if err := WritePatchFile(path, second, 0o644, false); err != nil {
+		t.Fatalf("WritePatchFile() = %v", err)
+	}
+	got, err = ParsePatches(path, "")
+	if err != nil {
+		t.Fatalf("ParsePatches() = %v", err)
+	}
+	want := []Patch{second[2], second[1], first[0], second[0]}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("second write (-want +got)\n%s", diff)
+	}
+
+	// Overwriting drops the existing patches.
+	if err := WritePatchFile(path, second[:1], 0o644, true); err != nil {
+		t.Fatalf("WritePatchFile() = %v", err)
+	}
+	got, err = ParsePatches(path, "")
+	if err != nil {
+		t.Fatalf("ParsePatches() = %v", err)
+	}
+	if diff := cmp.Diff(second[:1], got); diff != "" {
+		t.Errorf("overwrite (-want +got)\n%s", diff)
+	}
+}
+
+func TestWritePropertiesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "properties.yaml")
+
+	if err := WritePropertiesFile(path, map[string]string{"prop1": "value1", "prop2": "value2"}, 0o644, false); err != nil {
+		t.Fatalf("WritePropertiesFile() = %v", err)
+	}
+	if err := WritePropertiesFile(path, map[string]string{"prop2": "value2.1", "prop3": "value3"}, 0o640, false); err != nil {
+		t.Fatalf("WritePropertiesFile() = %v", err)
+	}
+	got, err := ParseProperties(path, "")
+	if err != nil {
+		t.Fatalf("ParseProperties() = %v", err)
+	}
+	want := map[string]string{"prop1": "value1", "prop2": "value2.1", "prop3": "value3"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got)\n%s", diff)
+	}
+
+	// The mode of the last write wins, even though the file existed.
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fi.Mode().Perm(); got != 0o640 {
+		t.Errorf("mode = %#o, want 0640", got)
+	}
+
+	// Without a mode, the existing permissions are kept.
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePropertiesFile(path, map[string]string{"prop1": "value1.1"}, 0, false); err != nil {
+		t.Fatalf("WritePropertiesFile() = %v", err)
+	}
+	if fi, err = os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := fi.Mode().Perm(); got != 0o600 {
+		t.Errorf("mode = %#o, want 0600", got)
+	}
+
+	// Overwriting drops the existing properties.
+	if err := WritePropertiesFile(path, map[string]string{"prop3": "value3.1"}, 0o640, true); err != nil {
+		t.Fatalf("WritePropertiesFile() = %v", err)
+	}
+	got, err = ParseProperties(path, "")
+	if err != nil {
+		t.Fatalf("ParseProperties() = %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"prop3": "value3.1"}, got); diff != "" {
+		t.Errorf("overwrite (-want +got)\n%s", diff)
+	}
+}
@@ -3,13 +3,12 @@ package pkg
 import (
 	"context"
 	"fmt"
-	"io"
-	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/chainguard-dev/clog"
 	"github.com/chainguard-dev/gopom"
-	"github.com/ghodss/yaml"
 )
 
 /* Example patch for 'proper' dependency:
@@ -34,6 +33,29 @@ type Patch struct {
 	Version    string `json:"version" yaml:"version"`
 	Scope      string `json:"scope,omitempty" yaml:"scope,omitempty"`
 	Type       string `json:"type,omitempty" yaml:"type,omitempty"`
+	// RenameTo, if set, moves the matched dependency to these new
+	// coordinates (e.g. javax.* to jakarta.*) in place, keeping its
+	// position, scope, and exclusions. Renames never add a dependency.
+	RenameTo *Coordinate `json:"renameTo,omitempty" yaml:"renameTo,omitempty"`
+	// Property, if set, is the property that holds the version of this
+	// dependency. The property is updated instead of the dependency, which
+	// is left alone.
+	Property string `json:"property,omitempty" yaml:"property,omitempty"`
+	// From, if set, is the version the dependency (or Property) is expected
+	// to be at. The patch is skipped with a warning if it is at any other
+	// version, or not in the project at all, so a stale patch never
+	// overwrites a version that has moved on.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	// Reason is why the patch is applied, e.g. the CVE it fixes. It is
+	// only carried along into the logs, the change summary and the
+	// --output-deps file.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Coordinate identifies a dependency without its version.
+type Coordinate struct {
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
 }
 
 type PropertyList struct {
@@ -58,37 +80,176 @@ const (
 	defaultType  = "jar"
 )
 
+// PatchOptions tweaks how PatchProjectWithOptions applies patches. The zero
+// value gives the default PatchProject behavior.
+type PatchOptions struct {
+	// PatchManagedDependencies also sets the version on a versionless
+	// dependency in Project.Dependencies when the same dependency is
+	// declared with a version in DependencyManagement. By default only the
+	// DependencyManagement entry is updated, so that the dependency keeps
+	// getting its version from there.
+	PatchManagedDependencies bool
+
+	// BumpPolicy refuses to patch existing dependencies whose version would
+	// move further than allowed, e.g. 4.1.x to 4.2.x under BumpPolicyPatch.
+	// Empty means no restriction.
+	BumpPolicy BumpPolicy
+
+	// DependencyManagementOnly only updates and adds versions in
+	// DependencyManagement, and never touches the versions in
+	// Project.Dependencies.
+	DependencyManagementOnly bool
+
+	// StrictVersions fails when a patch or property sets a LATEST or
+	// RELEASE version, instead of only warning about it.
+	StrictVersions bool
+
+	// RegexMatch treats the ArtifactID of the patches as a regular
+	// expression that has to match the whole artifactId of existing
+	// dependencies in the same group. Each dependency that matches is
+	// patched, and nothing is ever added for these patches.
+	RegexMatch bool
+
+	// NoAdd only updates existing dependencies, patches that do not match
+	// any are skipped instead of being added to DependencyManagement.
+	NoAdd bool
+
+	// ApplyScopeType also sets the scope and type of the patch on the
+	// dependencies it matches. By default those are never changed. The
+	// defaults ParsePatches fills in (import and jar) are not applied.
+	ApplyScopeType bool
+
+	// SortProperties sorts all the properties of the project by name. By
+	// default they keep their order, and new ones are added at the end.
+	SortProperties bool
+
+	// Summary, if set, gets every change (and no-op) recorded in it.
+	Summary *PatchSummary
+}
+
 // PatchProject will update versions for all matched dependencies
 // if they are found in Project.Dependencies. If there is no
 // match, it will add the dependency to the project.
 // Also does a blind overwrite of any properties with propertyPatches.
 // TODO(vaikas): Figure out when / if to use DependencyManagement instead.
 func PatchProject(ctx context.Context, project *gopom.Project, patches []Patch, propertyPatches map[string]string) (*gopom.Project, error) {
+	return PatchProjectWithOptions(ctx, project, patches, propertyPatches, PatchOptions{})
+}
+
+// PatchProjectWithOptions is PatchProject with the behavior tweaks in opts.
+func PatchProjectWithOptions(ctx context.Context, project *gopom.Project, patches []Patch, propertyPatches map[string]string, opts PatchOptions) (*gopom.Project, error) {
 	log := clog.FromContext(ctx)
 	if project == nil {
 		return nil, fmt.Errorf("project is nil")
 	}
+	if err := rejectMetaVersions(log, project, patches, propertyPatches, opts.StrictVersions); err != nil {
+		return nil, err
+	}
+	patches, err := expandWildcardPatches(log, project, patches)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RegexMatch {
+		if patches, err = expandRegexPatches(log, project, patches); err != nil {
+			return nil, err
+		}
+	}
+	// Patches that name the property holding the version update that
+	// property, and do not touch the dependencies at all.
+	dependencyPatches := make([]Patch, 0, len(patches))
+	for _, p := range patches {
+		if p.Property == "" {
+			dependencyPatches = append(dependencyPatches, p)
+			continue
+		}
+		if err := patchPropertyFor(log, project, p, opts.BumpPolicy, opts.Summary); err != nil {
+			return nil, err
+		}
+	}
+	patches = dependencyPatches
+
 	// If there are no straight up version replacements, but
 	// for some reason a dependency is missing, gather them here
 	// so that we can add them later.
 	missingDeps := make(map[Patch]Patch)
 	for _, p := range patches {
 		log.Infof("Have patch: %s.%s:%s", p.GroupID, p.ArtifactID, p.Version)
+		if looksTransposed(p) {
+			log.Warnf("Patch %s.%s looks like it has groupId and artifactId swapped, please double check it", p.GroupID, p.ArtifactID)
+		}
 		missingDeps[p] = p
 	}
 
+	// Coordinates of the patches that changed something, the rest are
+	// no-ops.
+	changed := make(map[string]bool)
+
+	// Dependencies that are declared in DependencyManagement. A versionless
+	// dependency that is also in here gets its version from there, so we
+	// only patch the DependencyManagement entry (unless asked otherwise).
+	managed := make(map[string]bool)
+	if project.DependencyManagement != nil && project.DependencyManagement.Dependencies != nil {
+		for _, dep := range *project.DependencyManagement.Dependencies {
+			managed[dep.GroupID+":"+dep.ArtifactID] = true
+		}
+	}
+
 	// If there are any hard coded dependencies that need to be patched, do
 	// that here.
 	// Note that we do not patch scope, or type, since they should already be
-	// configured correctly.
+	// configured correctly, unless asked to with ApplyScopeType.
 	if project.Dependencies != nil {
 		for i, dep := range *project.Dependencies {
 			log.Infof("Checking DEP: %s.%s:%s", dep.GroupID, dep.ArtifactID, dep.Version)
 			for _, patch := range patches {
 				if dep.ArtifactID == patch.ArtifactID &&
 					dep.GroupID == patch.GroupID {
-					log.Infof("Patching %s.%s from %s to %s with scope: %s", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, patch.Scope)
+					if !opts.ApplyScopeType {
+						for _, m := range scopeTypeMismatch(dep, patch) {
+							log.Warnf("Patch for %s.%s has %s, the patch may have been written for a different dependency", patch.GroupID, patch.ArtifactID, m)
+						}
+					}
+					if caseOnlyChange(dep.Version, patch.Version) {
+						log.Warnf("Patch for %s.%s changes the version from %s to %s, which only differ in case, this is likely unintended", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version)
+					}
+					if opts.DependencyManagementOnly {
+						if dep.Version != "" {
+							log.Warnf("Dependency %s.%s has version %s in dependencies which takes precedence over dependencyManagement, consider removing it", dep.GroupID, dep.ArtifactID, dep.Version)
+						}
+						if patch.RenameTo != nil {
+							renameDependency(log, &(*project.Dependencies)[i], *patch.RenameTo)
+							recordUpdate(opts.Summary, changed, dep, (*project.Dependencies)[i], patch.Reason)
+						}
+						continue
+					}
+					if dep.Version == "" && managed[dep.GroupID+":"+dep.ArtifactID] && !opts.PatchManagedDependencies {
+						log.Warnf("Dependency %s.%s is declared in both dependencies and dependencyManagement, only patching dependencyManagement", patch.GroupID, patch.ArtifactID)
+						// The version stays managed, but the coordinates
+						// still have to follow the rename.
+						if patch.RenameTo != nil {
+							renameDependency(log, &(*project.Dependencies)[i], *patch.RenameTo)
+							recordUpdate(opts.Summary, changed, dep, (*project.Dependencies)[i], patch.Reason)
+						}
+						continue
+					}
+					if refs := compositeVersion(dep.Version); len(refs) > 0 {
+						log.Warnf("Skipping %s.%s, its version %s is built from the properties %s and can not be replaced, patch one of them instead", patch.GroupID, patch.ArtifactID, dep.Version, strings.Join(refs, ", "))
+						delete(missingDeps, patch)
+						continue
+					}
+					if !fromMatches(log, project, dep, patch) || !bumpAllowed(log, opts.BumpPolicy, dep, patch) {
+						delete(missingDeps, patch)
+						continue
+					}
+					log.Infof("Patching %s.%s from %s to %s with scope: %s%s", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, patch.Scope, because(patch.Reason))
 					(*project.Dependencies)[i].Version = patch.Version
+					if opts.ApplyScopeType {
+						applyScopeType(log, &(*project.Dependencies)[i], patch)
+					}
+					if patch.RenameTo != nil {
+						renameDependency(log, &(*project.Dependencies)[i], *patch.RenameTo)
+					}
+					recordUpdate(opts.Summary, changed, dep, (*project.Dependencies)[i], patch.Reason)
 
 					// Found it, so remove it from the missing deps
 					// This is dump, make it better.
@@ -105,15 +266,39 @@ func PatchProject(ctx context.Context, project *gopom.Project, patches []Patch,
 	}
 
 	// Note that we do not patch scope, or type, since they should already be
-	// configured correctly.
+	// configured correctly, unless asked to with ApplyScopeType.
 	if project.DependencyManagement != nil {
 		for i, dep := range *project.DependencyManagement.Dependencies {
 			log.Debugf("Checking DM DEP: %s.%s:%s", dep.GroupID, dep.ArtifactID, dep.Version)
 			for _, patch := range patches {
 				if dep.ArtifactID == patch.ArtifactID &&
 					dep.GroupID == patch.GroupID {
-					log.Infof("Patching DM dep %s.%s from %s to %s with scope: %s", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, patch.Scope)
+					if !opts.ApplyScopeType {
+						for _, m := range scopeTypeMismatch(dep, patch) {
+							log.Warnf("Patch for DM dep %s.%s has %s, the patch may have been written for a different dependency", patch.GroupID, patch.ArtifactID, m)
+						}
+					}
+					if caseOnlyChange(dep.Version, patch.Version) {
+						log.Warnf("Patch for DM dep %s.%s changes the version from %s to %s, which only differ in case, this is likely unintended", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version)
+					}
+					if refs := compositeVersion(dep.Version); len(refs) > 0 {
+						log.Warnf("Skipping DM dep %s.%s, its version %s is built from the properties %s and can not be replaced, patch one of them instead", patch.GroupID, patch.ArtifactID, dep.Version, strings.Join(refs, ", "))
+						delete(missingDeps, patch)
+						continue
+					}
+					if !fromMatches(log, project, dep, patch) || !bumpAllowed(log, opts.BumpPolicy, dep, patch) {
+						delete(missingDeps, patch)
+						continue
+					}
+					log.Infof("Patching DM dep %s.%s from %s to %s with scope: %s%s", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, patch.Scope, because(patch.Reason))
 					(*project.DependencyManagement.Dependencies)[i].Version = patch.Version
+					if opts.ApplyScopeType {
+						applyScopeType(log, &(*project.DependencyManagement.Dependencies)[i], patch)
+					}
+					if patch.RenameTo != nil {
+						renameDependency(log, &(*project.DependencyManagement.Dependencies)[i], *patch.RenameTo)
+					}
+					recordUpdate(opts.Summary, changed, dep, (*project.DependencyManagement.Dependencies)[i], patch.Reason)
 					// Found it, so remove it from the missing deps
 					// This is dump, make it better.
 					delete(missingDeps, patch)
@@ -122,6 +307,30 @@ func PatchProject(ctx context.Context, project *gopom.Project, patches []Patch,
 		}
 	}
 
+	// Renames only ever apply to existing dependencies, so they are not
+	// missing.
+	for md := range missingDeps {
+		if md.RenameTo != nil {
+			log.Warnf("Not renaming %s.%s to %s.%s, dependency not found", md.GroupID, md.ArtifactID, md.RenameTo.GroupID, md.RenameTo.ArtifactID)
+			delete(missingDeps, md)
+		}
+	}
+
+	// Patches that expect a version are only for existing dependencies.
+	for md := range missingDeps {
+		if md.From != "" {
+			log.Warnf("Skipping %s.%s, expected it at %s but it is not in the project", md.GroupID, md.ArtifactID, md.From)
+			delete(missingDeps, md)
+		}
+	}
+
+	if opts.NoAdd {
+		for md := range missingDeps {
+			log.Infof("Not adding missing dependency: %s.%s:%s", md.GroupID, md.ArtifactID, md.Version)
+			delete(missingDeps, md)
+		}
+	}
+
 	// If there are any missing dependencies, add them in. I guess add them
 	// to DependencyManagement?
 	if project.DependencyManagement == nil && len(missingDeps) > 0 {
@@ -131,7 +340,7 @@ func PatchProject(ctx context.Context, project *gopom.Project, patches []Patch,
 	}
 	for md := range missingDeps {
 		md := md
-		log.Infof("Adding missing dependency: %s.%s:%s", md.GroupID, md.ArtifactID, md.Version)
+		log.Infof("Adding missing dependency: %s.%s:%s%s", md.GroupID, md.ArtifactID, md.Version, because(md.Reason))
 
 		*project.DependencyManagement.Dependencies = append(*project.DependencyManagement.Dependencies, gopom.Dependency{
 			GroupID:    md.GroupID,
@@ -140,44 +349,403 @@ func PatchProject(ctx context.Context, project *gopom.Project, patches []Patch,
 			Scope:      md.Scope,
 			Type:       md.Type,
 		})
+		opts.Summary.Record(Change{Kind: ChangeAdded, GroupID: md.GroupID, ArtifactID: md.ArtifactID, To: md.Version, Reason: md.Reason})
+		changed[md.GroupID+":"+md.ArtifactID] = true
+	}
+
+	for _, p := range patches {
+		if !changed[p.GroupID+":"+p.ArtifactID] {
+			opts.Summary.Record(Change{Kind: ChangeNoop, GroupID: p.GroupID, ArtifactID: p.ArtifactID, To: p.Version, Reason: p.Reason})
+		}
 	}
+
 	if project.Properties == nil && len(propertyPatches) > 0 {
-		project.Properties = &gopom.Properties{Entries: propertyPatches}
-	} else {
-		for k, v := range propertyPatches {
-			val, exists := project.Properties.Entries[k]
-			if exists {
-				log.Infof("Patching property: %s from %s to %s", k, val, v)
-			} else {
-				log.Infof("Creating property: %s as %s", k, v)
+		project.Properties = &gopom.Properties{Entries: map[string]string{}}
+	}
+	// Properties are marshaled in Order, go through them sorted so that new
+	// ones are added in a stable order.
+	names := make([]string, 0, len(propertyPatches))
+	for k := range propertyPatches {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	// Properties of a POM with modules are usually used by the modules.
+	var referenced map[string]bool
+	if project.Modules == nil && len(names) > 0 {
+		if r, err := referencedPropertyNames(project); err == nil {
+			referenced = r
+		}
+	}
+	for _, k := range names {
+		v := propertyPatches[k]
+		val, exists := project.Properties.Entries[k]
+		// A new property may override one that the parent uses, so only
+		// warn about those without a parent.
+		if referenced != nil && !referenced[k] && (exists || project.Parent == nil) {
+			log.Warnf("Property %s is not referenced anywhere in this POM, patching it may not change any version, e.g. the dependencies pin their versions inline", k)
+		}
+		if exists {
+			log.Infof("Patching property: %s from %s to %s", k, val, v)
+			if caseOnlyChange(val, v) {
+				log.Warnf("Property %s changes from %s to %s, which only differ in case, this is likely unintended", k, val, v)
 			}
-			project.Properties.Entries[k] = v
+		} else {
+			log.Infof("Creating property: %s as %s", k, v)
+			project.Properties.Order = append(project.Properties.Order, k)
 		}
+		project.Properties.Entries[k] = v
+		if exists && val == v {
+			opts.Summary.Record(Change{Kind: ChangeNoop, Property: k, To: v})
+		} else {
+			opts.Summary.Record(Change{Kind: ChangeProperty, Property: k, From: val, To: v})
+		}
+	}
+	if opts.SortProperties && project.Properties != nil {
+		sort.Strings(project.Properties.Order)
 	}
 	return project, nil
 }
 
+// patchPropertyFor updates the property named by the patch. The property must
+// exist, and the dependency is expected to reference it, but it is only a
+// warning if it does not since it may be used from a child module. The bump
+// policy applies to the property value as it does to a dependency version.
+func patchPropertyFor(log *clog.Logger, project *gopom.Project, patch Patch, policy BumpPolicy, summary *PatchSummary) error {
+	if project.Properties == nil {
+		return fmt.Errorf("property %s for %s.%s does not exist", patch.Property, patch.GroupID, patch.ArtifactID)
+	}
+	old, exists := project.Properties.Entries[patch.Property]
+	if !exists {
+		return fmt.Errorf("property %s for %s.%s does not exist", patch.Property, patch.GroupID, patch.ArtifactID)
+	}
+
+	if patch.From != "" && old != patch.From {
+		log.Warnf("Skipping property %s for %s.%s, expected it at %s but it is at %s", patch.Property, patch.GroupID, patch.ArtifactID, patch.From, old)
+		return nil
+	}
+	if !bumpAllowed(log, policy, gopom.Dependency{GroupID: patch.GroupID, ArtifactID: patch.ArtifactID, Version: old}, patch) {
+		return nil
+	}
+
+	referenced := false
+	for _, dep := range allDependencies(project) {
+		if dep.GroupID == patch.GroupID && dep.ArtifactID == patch.ArtifactID {
+			if dep.Version != "${"+patch.Property+"}" {
+				log.Warnf("Dependency %s.%s has version %s, not ${%s}", dep.GroupID, dep.ArtifactID, dep.Version, patch.Property)
+			}
+			referenced = true
+		}
+	}
+	if !referenced {
+		log.Warnf("Dependency %s.%s for property %s is not in the project", patch.GroupID, patch.ArtifactID, patch.Property)
+	}
+
+	if caseOnlyChange(old, patch.Version) {
+		log.Warnf("Property %s for %s.%s changes from %s to %s, which only differ in case, this is likely unintended", patch.Property, patch.GroupID, patch.ArtifactID, old, patch.Version)
+	}
+	log.Infof("Patching property: %s for %s.%s from %s to %s%s", patch.Property, patch.GroupID, patch.ArtifactID, old, patch.Version, because(patch.Reason))
+	project.Properties.Entries[patch.Property] = patch.Version
+	if old == patch.Version {
+		summary.Record(Change{Kind: ChangeNoop, Property: patch.Property, To: patch.Version, Reason: patch.Reason})
+	} else {
+		summary.Record(Change{Kind: ChangeProperty, Property: patch.Property, From: old, To: patch.Version, Reason: patch.Reason})
+	}
+	return nil
+}
+
+// recordUpdate records the change from before to after in the summary, if
+// anything did change, and marks the patch for it as changed.
+func recordUpdate(summary *PatchSummary, changed map[string]bool, before, after gopom.Dependency, reason string) {
+	if before == after {
+		return
+	}
+	c := Change{Kind: ChangeUpdated, GroupID: after.GroupID, ArtifactID: after.ArtifactID, From: before.Version, To: after.Version, Reason: reason}
+	if before.GroupID != after.GroupID || before.ArtifactID != after.ArtifactID {
+		c.RenamedFrom = &Coordinate{GroupID: before.GroupID, ArtifactID: before.ArtifactID}
+	}
+	summary.Record(c)
+	changed[before.GroupID+":"+before.ArtifactID] = true
+}
+
+// because formats the reason of a patch for the logs.
+func because(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + reason + ")"
+}
+
+// applyScopeType sets the scope and type of the patch on dep, except for the
+// defaults ParsePatches fills in.
+func applyScopeType(log *clog.Logger, dep *gopom.Dependency, patch Patch) {
+	if patch.Scope != "" && patch.Scope != defaultScope && patch.Scope != dep.Scope {
+		log.Infof("Changing scope of %s.%s from %s to %s", dep.GroupID, dep.ArtifactID, dep.Scope, patch.Scope)
+		dep.Scope = patch.Scope
+	}
+	if patch.Type != "" && patch.Type != defaultType && patch.Type != dep.Type {
+		log.Infof("Changing type of %s.%s from %s to %s", dep.GroupID, dep.ArtifactID, dep.Type, patch.Type)
+		dep.Type = patch.Type
+	}
+}
+
+// renameDependency moves dep to the new coordinates.
+func renameDependency(log *clog.Logger, dep *gopom.Dependency, to Coordinate) {
+	log.Infof("Renaming %s.%s to %s.%s", dep.GroupID, dep.ArtifactID, to.GroupID, to.ArtifactID)
+	dep.GroupID = to.GroupID
+	dep.ArtifactID = to.ArtifactID
+}
+
+// fromMatches returns true if the patch does not expect a version, or dep is
+// at the expected version. Versions that come from properties of the project
+// are resolved first.
+func fromMatches(log *clog.Logger, project *gopom.Project, dep gopom.Dependency, patch Patch) bool {
+	if patch.From == "" {
+		return true
+	}
+	current := dep.Version
+	if project.Properties != nil {
+		if v, ok := resolveVersion(current, project.Properties.Entries); ok {
+			current = v
+		}
+	}
+	if current != patch.From {
+		log.Warnf("Skipping %s.%s, expected it at %s but it is at %s", patch.GroupID, patch.ArtifactID, patch.From, current)
+		return false
+	}
+	return true
+}
+
+// bumpAllowed checks moving dep to the patch version against the bump policy,
+// and logs why if it is not allowed. Versions that can not be parsed are let
+// through with a warning.
+func bumpAllowed(log *clog.Logger, policy BumpPolicy, dep gopom.Dependency, patch Patch) bool {
+	if dep.Version == "" || policy == "" || policy == BumpPolicyMajor {
+		return true
+	}
+	allowed, parsed := allowedByPolicy(policy, dep.Version, patch.Version)
+	if !parsed {
+		log.Warnf("Can not check %s.%s from %s to %s against bump policy %s, patching anyway", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, policy)
+		return true
+	}
+	if !allowed {
+		log.Warnf("Skipping %s.%s from %s to %s, not allowed by bump policy %s", patch.GroupID, patch.ArtifactID, dep.Version, patch.Version, policy)
+	}
+	return allowed
+}
+
+func dependencies(deps *[]gopom.Dependency) []gopom.Dependency {
+	if deps == nil {
+		return nil
+	}
+	return *deps
+}
+
+func managedDependencies(project *gopom.Project) []gopom.Dependency {
+	if project.DependencyManagement == nil {
+		return nil
+	}
+	return dependencies(project.DependencyManagement.Dependencies)
+}
+
+// allDependencies returns the dependencies followed by the managed ones.
+func allDependencies(project *gopom.Project) []gopom.Dependency {
+	var all []gopom.Dependency
+	all = append(all, dependencies(project.Dependencies)...)
+	return append(all, managedDependencies(project)...)
+}
+
+// dependencyProperties returns the names of the properties that the versions
+// of the project's dependencies reference, directly or through other
+// properties. Property values are looked up in propertyPatches first, then in
+// the project.
+func dependencyProperties(project *gopom.Project, propertyPatches map[string]string) map[string]bool {
+	value := func(name string) string {
+		if v, ok := propertyPatches[name]; ok {
+			return v
+		}
+		if project.Properties != nil {
+			return project.Properties.Entries[name]
+		}
+		return ""
+	}
+	referenced := map[string]bool{}
+	var visit func(v string)
+	visit = func(v string) {
+		for _, m := range propertyRefRe.FindAllStringSubmatch(v, -1) {
+			if !referenced[m[1]] {
+				referenced[m[1]] = true
+				visit(value(m[1]))
+			}
+		}
+	}
+	for _, dep := range allDependencies(project) {
+		visit(dep.Version)
+	}
+	return referenced
+}
+
+// rejectMetaVersions warns about, or with strict fails on, patches and
+// properties that set a LATEST or RELEASE version.
+func rejectMetaVersions(log *clog.Logger, project *gopom.Project, patches []Patch, propertyPatches map[string]string, strict bool) error {
+	var bad []string
+	for _, p := range patches {
+		if isMetaVersion(p.Version) {
+			bad = append(bad, fmt.Sprintf("patch %s.%s", p.GroupID, p.ArtifactID))
+		}
+	}
+	names := make([]string, 0, len(propertyPatches))
+	for k := range propertyPatches {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	// Properties that are not dependency versions, e.g. a build.type of
+	// RELEASE, are fine.
+	versions := dependencyProperties(project, propertyPatches)
+	for _, k := range names {
+		if versions[k] && isMetaVersion(propertyPatches[k]) {
+			bad = append(bad, fmt.Sprintf("property %s", k))
+		}
+	}
+	for _, b := range bad {
+		if strict {
+			return fmt.Errorf("%s uses a LATEST or RELEASE version, which is deprecated and not reproducible", b)
+		}
+		log.Warnf("%s uses a LATEST or RELEASE version, which is deprecated and not reproducible", b)
+	}
+	return nil
+}
+
+// wildcardArtifactID matches every artifactId of the group in a patch, which
+// must then have a From.
+const wildcardArtifactID = "*"
+
+// expandWildcardPatches replaces each patch with the wildcard artifactId with
+// one patch for every existing dependency of the same group that is at the
+// From version of the patch. Patches that match nothing are dropped.
+func expandWildcardPatches(log *clog.Logger, project *gopom.Project, patches []Patch) ([]Patch, error) {
+	var props map[string]string
+	if project.Properties != nil {
+		props = project.Properties.Entries
+	}
+	expanded := make([]Patch, 0, len(patches))
+	for _, p := range patches {
+		if p.ArtifactID != wildcardArtifactID {
+			expanded = append(expanded, p)
+			continue
+		}
+		if p.From == "" {
+			return nil, fmt.Errorf("patch %s.%s needs a from version to match artifactIds against", p.GroupID, p.ArtifactID)
+		}
+		if p.Property != "" || p.RenameTo != nil {
+			return nil, fmt.Errorf("patch %s.%s can not have a property or renameTo", p.GroupID, p.ArtifactID)
+		}
+		seen := map[string]bool{}
+		for _, dep := range allDependencies(project) {
+			if dep.GroupID != p.GroupID || seen[dep.ArtifactID] {
+				continue
+			}
+			if v, _ := resolveVersion(dep.Version, props); v != p.From {
+				continue
+			}
+			seen[dep.ArtifactID] = true
+			match := p
+			match.ArtifactID = dep.ArtifactID
+			expanded = append(expanded, match)
+		}
+		if len(seen) == 0 {
+			log.Warnf("Patch %s.%s matched no dependencies at %s, skipping it", p.GroupID, p.ArtifactID, p.From)
+			continue
+		}
+		log.Infof("Patch %s.%s matched %d dependencies at %s", p.GroupID, p.ArtifactID, len(seen), p.From)
+	}
+	return expanded, nil
+}
+
+// maxRegexLength bounds the length of artifactId regular expressions. Go
+// regular expressions run in linear time, but very long ones are slow to
+// compile and are most likely a mistake.
+const maxRegexLength = 256
+
+// expandRegexPatches replaces each patch, whose ArtifactID is a regular
+// expression, with one patch for every existing dependency of the same group
+// whose artifactId it matches. Patches that match nothing are dropped.
+func expandRegexPatches(log *clog.Logger, project *gopom.Project, patches []Patch) ([]Patch, error) {
+	expanded := make([]Patch, 0, len(patches))
+	for _, p := range patches {
+		if len(p.ArtifactID) > maxRegexLength {
+			return nil, fmt.Errorf("artifactId regular expression for %s is longer than %d characters", p.GroupID, maxRegexLength)
+		}
+		re, err := regexp.Compile("^(?:" + p.ArtifactID + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid artifactId regular expression %q for %s: %w", p.ArtifactID, p.GroupID, err)
+		}
+		seen := map[string]bool{}
+		for _, dep := range allDependencies(project) {
+			if dep.GroupID != p.GroupID || !re.MatchString(dep.ArtifactID) || seen[dep.ArtifactID] {
+				continue
+			}
+			seen[dep.ArtifactID] = true
+			match := p
+			match.ArtifactID = dep.ArtifactID
+			expanded = append(expanded, match)
+		}
+		if len(seen) == 0 {
+			log.Warnf("Patch %s.%s matched no dependencies, skipping it", p.GroupID, p.ArtifactID)
+			continue
+		}
+		log.Infof("Patch %s.%s matched %d dependencies", p.GroupID, p.ArtifactID, len(seen))
+	}
+	return expanded, nil
+}
+
+// scopeTypeMismatch describes how the scope and type of the patch differ
+// from the ones of the dependency it matched. Those are never patched, so a
+// difference usually means a mistake in the patch. The defaults ParsePatches
+// fills in (import and jar) are treated as not specified.
+func scopeTypeMismatch(dep gopom.Dependency, patch Patch) []string {
+	var mismatches []string
+	depScope := dep.Scope
+	if depScope == "" {
+		depScope = "compile"
+	}
+	if patch.Scope != "" && patch.Scope != defaultScope && patch.Scope != depScope {
+		mismatches = append(mismatches, fmt.Sprintf("scope %s but the dependency has scope %s", patch.Scope, depScope))
+	}
+	depType := dep.Type
+	if depType == "" {
+		depType = defaultType
+	}
+	if patch.Type != "" && patch.Type != defaultType && patch.Type != depType {
+		mismatches = append(mismatches, fmt.Sprintf("type %s but the dependency has type %s", patch.Type, depType))
+	}
+	return mismatches
+}
+
+// looksTransposed returns true if the patch coordinates look like the groupId
+// and artifactId have been swapped. GroupIDs are conventionally dotted
+// (reverse domain), so an artifactId with dots next to a groupId without any
+// is suspicious. Some artifactIds legitimately have dots, so this is only a
+// hint, not an error.
+func looksTransposed(p Patch) bool {
+	return !strings.Contains(p.GroupID, ".") && strings.Contains(p.ArtifactID, ".")
+}
+
 func ParsePatches(patchFile, patchFlag string) ([]Patch, error) {
 	if patchFile != "" {
-		var patchList PatchList
-		file, err := os.Open(patchFile)
+		// Patch files can be a stream of several documents, each with some
+		// of the patches.
+		docs, err := decodeDocuments[PatchList](patchFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed reading file: %w", err)
-		}
-		defer file.Close()
-		byteValue, _ := io.ReadAll(file)
-		if err := yaml.Unmarshal(byteValue, &patchList); err != nil {
 			return nil, err
 		}
-		for i := range patchList.Patches {
-			if patchList.Patches[i].Scope == "" {
-				patchList.Patches[i].Scope = defaultScope
-			}
-			if patchList.Patches[i].Type == "" {
-				patchList.Patches[i].Type = defaultType
-			}
+		var patches []Patch
+		for _, doc := range docs {
+			patches = append(patches, doc.Patches...)
+		}
+		if err := defaultPatches(patches); err != nil {
+			return nil, err
 		}
-		return patchList.Patches, nil
+		return patches, nil
 	}
 	dependencies := strings.Split(patchFlag, " ")
 	patches := []Patch{}
@@ -185,9 +753,18 @@ func ParsePatches(patchFile, patchFlag string) ([]Patch, error) {
 		if dep == "" {
 			continue
 		}
-		parts := strings.Split(dep, "@")
+		// from:<version> sets the version the previous dependency is
+		// expected to be at.
+		if from, ok := strings.CutPrefix(dep, "from:"); ok {
+			if len(patches) == 0 || from == "" {
+				return nil, fmt.Errorf("invalid dependencies format (%s), from:<version> has to follow a dependency", dep)
+			}
+			patches[len(patches)-1].From = from
+			continue
+		}
+		parts := splitCoordinates(dep)
 		if len(parts) < 3 {
-			return nil, fmt.Errorf("invalid dependencies format (%s). Each dependency should be in the format <groupID@artifactID@version[@scope]>. Usage: pombump --dependencies=\"<groupID@artifactID@version@scope> <groupID@artifactID@version> ...\"", dep)
+			return nil, fmt.Errorf("invalid dependencies format (%s). Each dependency should be in the format <groupID@artifactID@version[@scope]> or <groupID:artifactID:version>. Usage: pombump --dependencies=\"<groupID@artifactID@version@scope> <groupID@artifactID@version> ...\"", dep)
 		}
 		// Default scope. Maybe make this configurable?
 		scope := defaultScope
@@ -203,21 +780,60 @@ func ParsePatches(patchFile, patchFlag string) ([]Patch, error) {
 	return patches, nil
 }
 
+// defaultPatches checks the patches read from a file, and fills in the
+// default scope and type.
+func defaultPatches(patches []Patch) error {
+	for i := range patches {
+		if rt := patches[i].RenameTo; rt != nil && (rt.GroupID == "" || rt.ArtifactID == "") {
+			return fmt.Errorf("invalid renameTo for %s.%s, both groupId and artifactId are required", patches[i].GroupID, patches[i].ArtifactID)
+		}
+		if patches[i].Scope == "" {
+			patches[i].Scope = defaultScope
+		}
+		if patches[i].Type == "" {
+			patches[i].Type = defaultType
+		}
+	}
+	return nil
+}
+
+// MergePatches appends the extra patches for dependencies that patches do
+// not already patch, so the patches given first win.
+func MergePatches(patches, extra []Patch) []Patch {
+	patched := map[string]bool{}
+	for _, p := range patches {
+		patched[p.GroupID+":"+p.ArtifactID] = true
+	}
+	for _, p := range extra {
+		if !patched[p.GroupID+":"+p.ArtifactID] {
+			patches = append(patches, p)
+		}
+	}
+	return patches
+}
+
+// splitCoordinates splits group@artifact@version[@scope[@type]], or Gradle
+// style group:artifact:version where anything after the second colon is the
+// version.
+func splitCoordinates(dep string) []string {
+	if !strings.Contains(dep, "@") && strings.Contains(dep, ":") {
+		return strings.SplitN(dep, ":", 3)
+	}
+	return strings.Split(dep, "@")
+}
+
 func ParseProperties(propertyFile, propertiesFlag string) (map[string]string, error) {
 	propertiesPatches := map[string]string{}
 	if propertyFile != "" {
-		var propertyList PropertyList
-		file, err := os.Open(propertyFile)
+		docs, err := decodeDocuments[PropertyList](propertyFile)
 		if err != nil {
-			return nil, fmt.Errorf("failed reading file: %w", err)
-		}
-		defer file.Close()
-		byteValue, _ := io.ReadAll(file)
-		if err := yaml.Unmarshal(byteValue, &propertyList); err != nil {
 			return nil, err
 		}
-		for _, v := range propertyList.Properties {
-			propertiesPatches[v.Property] = v.Value
+		// Later documents win.
+		for _, doc := range docs {
+			for _, v := range doc.Properties {
+				propertiesPatches[v.Property] = v.Value
+			}
 		}
 		return propertiesPatches, nil
 	}
@@ -1,8 +1,11 @@
 package pkg
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/chainguard-dev/gopom"
@@ -27,27 +30,219 @@ func TestSimplePoms(t *testing.T) {
 		in      *gopom.Project
 		patches []Patch
 		props   map[string]string
+		opts    PatchOptions
 		want    *gopom.Project
 	}{{
 		name:    "simple dependency, bumped inline, type and scope unmodified",
 		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0", "import", "jar")}},
-		patches: []Patch{{"a1", "b1", "1.0.1", "INVALID_SCOPE", "INVALID_TYPE"}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", Scope: "INVALID_SCOPE", Type: "INVALID_TYPE"}},
 		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.1", "import", "jar")}},
 	}, {
 		name:    "simple dependencymanagement, bumped inline, type and scope unmodified",
 		in:      &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a2", "b2", "2.0.0", "compile", "pom")}}},
-		patches: []Patch{{"a2", "b2", "2.0.1", "INVALID_SCOPE", "INVALID_TYPE"}},
+		patches: []Patch{{GroupID: "a2", ArtifactID: "b2", Version: "2.0.1", Scope: "INVALID_SCOPE", Type: "INVALID_TYPE"}},
 		want:    &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a2", "b2", "2.0.1", "compile", "pom")}}},
 	}, {
 		name:    "dependencymanagement, added to dependency management",
 		in:      &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("other", "b3", "2.0.0")}}},
-		patches: []Patch{{"added", "b", "2.0.1", "import", "somethingelse"}},
+		patches: []Patch{{GroupID: "added", ArtifactID: "b", Version: "2.0.1", Scope: "import", Type: "somethingelse"}},
 		want:    &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("other", "b3", "2.0.0"), makeDep("added", "b", "2.0.1", "import", "somethingelse")}}},
+	}, {
+		name: "dependency managed by dependencymanagement, only dependencymanagement bumped",
+		in: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a4", "b4", "")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a4", "b4", "4.0.0")}},
+		},
+		patches: []Patch{{GroupID: "a4", ArtifactID: "b4", Version: "4.0.1", Scope: "import", Type: "jar"}},
+		want: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a4", "b4", "")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a4", "b4", "4.0.1")}},
+		},
+	}, {
+		name: "dependency managed by dependencymanagement, both bumped when asked",
+		in: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a5", "b5", "")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a5", "b5", "5.0.0")}},
+		},
+		patches: []Patch{{GroupID: "a5", ArtifactID: "b5", Version: "5.0.1", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{PatchManagedDependencies: true},
+		want: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a5", "b5", "5.0.1")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a5", "b5", "5.0.1")}},
+		},
+	}, {
+		name:    "bump policy, crossing the boundary is skipped and not added",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a6", "b6", "4.1.94.Final"), makeDep("a7", "b7", "1.0.0")}},
+		patches: []Patch{{GroupID: "a6", ArtifactID: "b6", Version: "4.2.0.Final", Scope: "import", Type: "jar"}, {GroupID: "a7", ArtifactID: "b7", Version: "1.0.1", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{BumpPolicy: BumpPolicyPatch},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a6", "b6", "4.1.94.Final"), makeDep("a7", "b7", "1.0.1")}},
+	}, {
+		name: "rename, coordinates and version updated in place, exclusions kept",
+		in: &gopom.Project{Dependencies: &[]gopom.Dependency{
+			makeDep("first", "dep", "1.0.0"),
+			{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api", Version: "4.0.1", Scope: "provided", Exclusions: &[]gopom.Exclusion{{GroupID: "x", ArtifactID: "y"}}},
+			makeDep("last", "dep", "1.0.0"),
+		}},
+		patches: []Patch{{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api", Version: "6.0.0", RenameTo: &Coordinate{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api"}}},
+		want: &gopom.Project{Dependencies: &[]gopom.Dependency{
+			makeDep("first", "dep", "1.0.0"),
+			{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api", Version: "6.0.0", Scope: "provided", Exclusions: &[]gopom.Exclusion{{GroupID: "x", ArtifactID: "y"}}},
+			makeDep("last", "dep", "1.0.0"),
+		}},
+	}, {
+		name:    "rename that matches nothing is not added",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a8", "b8", "1.0.0")}},
+		patches: []Patch{{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api", Version: "6.0.0", RenameTo: &Coordinate{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api"}}},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a8", "b8", "1.0.0")}},
+	}, {
+		name: "dependencymanagement only, plain dependency untouched and managed one added",
+		in: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a9", "b9", "1.0.0"), makeDep("a10", "b10", "")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a10", "b10", "1.0.0")}},
+		},
+		patches: []Patch{{GroupID: "a9", ArtifactID: "b9", Version: "1.0.1", Scope: "import", Type: "jar"}, {GroupID: "a10", ArtifactID: "b10", Version: "1.0.1", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{DependencyManagementOnly: true, PatchManagedDependencies: true},
+		want: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("a9", "b9", "1.0.0"), makeDep("a10", "b10", "")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a10", "b10", "1.0.1"), makeDep("a9", "b9", "1.0.1")}},
+		},
+	}, {
+		name: "patch with property, property bumped and dependency untouched",
+		in: &gopom.Project{
+			Properties:   &gopom.Properties{Entries: map[string]string{"b11.version": "1.0.0"}, Order: []string{"b11.version"}},
+			Dependencies: &[]gopom.Dependency{makeDep("a11", "b11", "${b11.version}")},
+		},
+		patches: []Patch{{GroupID: "a11", ArtifactID: "b11", Version: "1.0.1", Property: "b11.version"}},
+		want: &gopom.Project{
+			Properties:   &gopom.Properties{Entries: map[string]string{"b11.version": "1.0.1"}, Order: []string{"b11.version"}},
+			Dependencies: &[]gopom.Dependency{makeDep("a11", "b11", "${b11.version}")},
+		},
+	}, {
+		name: "patch with property, bump policy, crossing the boundary is skipped",
+		in: &gopom.Project{
+			Properties:   &gopom.Properties{Entries: map[string]string{"b11.version": "1.0.0"}, Order: []string{"b11.version"}},
+			Dependencies: &[]gopom.Dependency{makeDep("a11", "b11", "${b11.version}")},
+		},
+		patches: []Patch{{GroupID: "a11", ArtifactID: "b11", Version: "2.0.0", Property: "b11.version"}},
+		opts:    PatchOptions{BumpPolicy: BumpPolicyMinor},
+		want: &gopom.Project{
+			Properties:   &gopom.Properties{Entries: map[string]string{"b11.version": "1.0.0"}, Order: []string{"b11.version"}},
+			Dependencies: &[]gopom.Dependency{makeDep("a11", "b11", "${b11.version}")},
+		},
+	}, {
+		name: "no add, existing dependency patched and missing one skipped",
+		in: &gopom.Project{
+			Dependencies: &[]gopom.Dependency{makeDep("a12", "b12", "1.0.0")},
+		},
+		patches: []Patch{{GroupID: "a12", ArtifactID: "b12", Version: "1.0.1", Scope: "import", Type: "jar"}, {GroupID: "a13", ArtifactID: "b13", Version: "1.0.1", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{NoAdd: true},
+		want: &gopom.Project{
+			Dependencies: &[]gopom.Dependency{makeDep("a12", "b12", "1.0.1")},
+		},
+	}, {
+		name: "regex match, matching dependencies patched and nothing added",
+		in: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("org.springframework.boot", "spring-boot-starter-web", "3.2.0"), makeDep("org.springframework.boot", "spring-boot-autoconfigure", "3.2.0")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("org.springframework.boot", "spring-boot-starter-json", "3.2.0")}},
+		},
+		patches: []Patch{{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-.*", Version: "3.2.5", Scope: "import", Type: "jar"}, {GroupID: "org.springframework.boot", ArtifactID: "nothing-.*", Version: "3.2.5", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{RegexMatch: true},
+		want: &gopom.Project{
+			Dependencies:         &[]gopom.Dependency{makeDep("org.springframework.boot", "spring-boot-starter-web", "3.2.5"), makeDep("org.springframework.boot", "spring-boot-autoconfigure", "3.2.0")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("org.springframework.boot", "spring-boot-starter-json", "3.2.5")}},
+		},
+	}, {
+		name:  "new properties, appended in order",
+		in:    &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"b.version": "1.0.0"}, Order: []string{"b.version"}}},
+		props: map[string]string{"c.version": "2.0.0", "a.version": "3.0.0"},
+		want:  &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"a.version": "3.0.0", "b.version": "1.0.0", "c.version": "2.0.0"}, Order: []string{"b.version", "a.version", "c.version"}}},
+	}, {
+		name:  "new properties, no properties section",
+		in:    &gopom.Project{},
+		props: map[string]string{"a.version": "1.0.0"},
+		want:  &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"a.version": "1.0.0"}, Order: []string{"a.version"}}},
+	}, {
+		name:    "from, matches",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0")}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", From: "1.0.0"}},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.1")}},
+	}, {
+		name: "from, matches the property value",
+		in: &gopom.Project{
+			Properties:           &gopom.Properties{Entries: map[string]string{"b1.version": "1.0.0"}, Order: []string{"b1.version"}},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "${b1.version}")}},
+		},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", From: "1.0.0"}},
+		want: &gopom.Project{
+			Properties:           &gopom.Properties{Entries: map[string]string{"b1.version": "1.0.0"}, Order: []string{"b1.version"}},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.1")}},
+		},
+	}, {
+		name:    "from, moved on, skipped",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.2")}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", From: "1.0.0"}},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.2")}},
+	}, {
+		name:    "from, missing, not added",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0")}},
+		patches: []Patch{{GroupID: "a2", ArtifactID: "b2", Version: "2.0.1", From: "2.0.0"}},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0")}},
+	}, {
+		name:    "from, property moved on, skipped",
+		in:      &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"b1.version": "1.0.2"}, Order: []string{"b1.version"}}, Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "${b1.version}")}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", From: "1.0.0", Property: "b1.version"}},
+		want:    &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"b1.version": "1.0.2"}, Order: []string{"b1.version"}}, Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "${b1.version}")}},
+	}, {
+		name:    "composite version, skipped",
+		in:      &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "${b1.major}.${b1.minor}.Final")}}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.2.Final"}},
+		want:    &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "${b1.major}.${b1.minor}.Final")}}},
+	}, {
+		name:    "apply scope and type",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0", "compile", "jar")}},
+		patches: []Patch{{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", Scope: "test", Type: "test-jar"}},
+		opts:    PatchOptions{ApplyScopeType: true},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.1", "test", "test-jar")}},
+	}, {
+		name:    "apply scope and type, defaults are not applied",
+		in:      &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a2", "b2", "2.0.0", "compile", "pom")}}},
+		patches: []Patch{{GroupID: "a2", ArtifactID: "b2", Version: "2.0.1", Scope: "import", Type: "jar"}},
+		opts:    PatchOptions{ApplyScopeType: true},
+		want:    &gopom.Project{DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("a2", "b2", "2.0.1", "compile", "pom")}}},
+	}, {
+		name: "wildcard artifactId, only the ones at from",
+		in: &gopom.Project{
+			Properties:           &gopom.Properties{Entries: map[string]string{"netty.version": "4.1.94.Final"}, Order: []string{"netty.version"}},
+			Dependencies:         &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "4.1.94.Final"), makeDep("io.netty", "netty-codec", "4.1.100.Final"), makeDep("other", "netty-handler", "4.1.94.Final")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-buffer", "${netty.version}")}},
+		},
+		patches: []Patch{{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final", From: "4.1.94.Final"}},
+		want: &gopom.Project{
+			Properties:           &gopom.Properties{Entries: map[string]string{"netty.version": "4.1.94.Final"}, Order: []string{"netty.version"}},
+			Dependencies:         &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "4.1.118.Final"), makeDep("io.netty", "netty-codec", "4.1.100.Final"), makeDep("other", "netty-handler", "4.1.94.Final")},
+			DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-buffer", "4.1.118.Final")}},
+		},
+	}, {
+		name:    "wildcard artifactId, nothing at from",
+		in:      &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "4.1.100.Final")}},
+		patches: []Patch{{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final", From: "4.1.94.Final"}},
+		want:    &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "4.1.100.Final")}},
+	}, {
+		name:  "new properties, sorted",
+		in:    &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"d.version": "1.0.0", "b.version": "1.0.0"}, Order: []string{"d.version", "b.version"}}},
+		props: map[string]string{"c.version": "2.0.0", "a.version": "3.0.0"},
+		opts:  PatchOptions{SortProperties: true},
+		want:  &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"a.version": "3.0.0", "b.version": "1.0.0", "c.version": "2.0.0", "d.version": "1.0.0"}, Order: []string{"a.version", "b.version", "c.version", "d.version"}}},
+	}, {
+		name: "sorted, no properties section",
+		in:   &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0")}},
+		opts: PatchOptions{SortProperties: true},
+		want: &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a1", "b1", "1.0.0")}},
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			in := tc.in
-			got, err := PatchProject(context.Background(), in, tc.patches, tc.props)
+			got, err := PatchProjectWithOptions(context.Background(), in, tc.patches, tc.props, tc.opts)
 			if err != nil {
 				t.Errorf("%s: Failed to patch %+v: %v", tc.name, tc.in, err)
 			}
@@ -58,6 +253,150 @@ func TestSimplePoms(t *testing.T) {
 	}
 }
 
+func TestPatchInvalidRegex(t *testing.T) {
+	for _, artifactID := range []string{"spring-boot-(", strings.Repeat("a", maxRegexLength+1)} {
+		in := &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("a", "b", "1.0.0")}}
+		patches := []Patch{{GroupID: "a", ArtifactID: artifactID, Version: "1.0.1"}}
+		if _, err := PatchProjectWithOptions(context.Background(), in, patches, nil, PatchOptions{RegexMatch: true}); err == nil {
+			t.Errorf("PatchProjectWithOptions() with artifactId %q did not fail", artifactID)
+		}
+	}
+}
+
+func TestPatchWildcardWithoutFrom(t *testing.T) {
+	in := &gopom.Project{Dependencies: &[]gopom.Dependency{makeDep("io.netty", "netty-handler", "4.1.94.Final")}}
+	patches := []Patch{{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final"}}
+	if _, err := PatchProject(context.Background(), in, patches, nil); err == nil {
+		t.Errorf("PatchProject() with a wildcard artifactId and no from did not fail")
+	}
+}
+
+func TestPatchMetaVersions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		patches []Patch
+		props   map[string]string
+		strict  bool
+		wantErr bool
+	}{{
+		name:    "patch, warning only",
+		patches: []Patch{{GroupID: "a", ArtifactID: "b", Version: "LATEST"}},
+	}, {
+		name:    "patch, strict",
+		patches: []Patch{{GroupID: "a", ArtifactID: "b", Version: "LATEST"}},
+		strict:  true,
+		wantErr: true,
+	}, {
+		name:    "property, strict",
+		props:   map[string]string{"b.version": "RELEASE"},
+		strict:  true,
+		wantErr: true,
+	}, {
+		name:   "plain versions, strict",
+		props:  map[string]string{"b.version": "1.0.1"},
+		strict: true,
+	}, {
+		name:   "property that is not a dependency version, strict",
+		props:  map[string]string{"build.type": "RELEASE"},
+		strict: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := &gopom.Project{
+				Properties:   &gopom.Properties{Entries: map[string]string{"b.version": "1.0.0"}, Order: []string{"b.version"}},
+				Dependencies: &[]gopom.Dependency{makeDep("a", "b", "${b.version}")},
+			}
+			_, err := PatchProjectWithOptions(context.Background(), in, tc.patches, tc.props, PatchOptions{StrictVersions: tc.strict})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("%s: PatchProjectWithOptions() = %v, wantErr %v", tc.name, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatchMissingProperty(t *testing.T) {
+	for _, in := range []*gopom.Project{
+		{Dependencies: &[]gopom.Dependency{makeDep("a", "b", "${b.version}")}},
+		{Properties: &gopom.Properties{Entries: map[string]string{"other": "1.0.0"}}, Dependencies: &[]gopom.Dependency{makeDep("a", "b", "${b.version}")}},
+	} {
+		patches := []Patch{{GroupID: "a", ArtifactID: "b", Version: "1.0.1", Property: "b.version"}}
+		if _, err := PatchProject(context.Background(), in, patches, nil); err == nil {
+			t.Errorf("PatchProject(%+v) with a missing property did not fail", in)
+		}
+	}
+}
+
+// Properties are marshaled in Order, so ones created by a patch must be
+// added to it or they are dropped from the output.
+func TestPatchNewPropertiesMarshaled(t *testing.T) {
+	parsedPom, err := gopom.Parse("testdata/zookeeper.pom.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := map[string]string{"new.b.version": "2.0.0", "new.a.version": "1.0.0"}
+	patched, err := PatchProject(context.Background(), parsedPom, nil, props)
+	if err != nil {
+		t.Fatalf("PatchProject() = %v", err)
+	}
+	out, err := patched.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := bytes.Index(out, []byte("<new.a.version>1.0.0</new.a.version>"))
+	b := bytes.Index(out, []byte("<new.b.version>2.0.0</new.b.version>"))
+	if a < 0 || b < 0 {
+		t.Fatalf("marshaled pom is missing the new properties:\n%s", out)
+	}
+	if a > b {
+		t.Errorf("new properties are not marshaled in sorted order:\n%s", out)
+	}
+}
+
+func TestMergePatches(t *testing.T) {
+	patches := []Patch{{GroupID: "a", ArtifactID: "b", Version: "1.0.0"}}
+	extra := []Patch{{GroupID: "a", ArtifactID: "b", Version: "2.0.0"}, {GroupID: "a", ArtifactID: "c", Version: "3.0.0"}}
+	want := []Patch{{GroupID: "a", ArtifactID: "b", Version: "1.0.0"}, {GroupID: "a", ArtifactID: "c", Version: "3.0.0"}}
+	if diff := cmp.Diff(want, MergePatches(patches, extra)); diff != "" {
+		t.Errorf("MergePatches() (-want +got)\n%s", diff)
+	}
+}
+
+func TestDependencyProperties(t *testing.T) {
+	project := &gopom.Project{
+		Properties: &gopom.Properties{Entries: map[string]string{
+			"netty.version": "${netty.major}.94.Final",
+			"netty.major":   "4.1",
+			"unused":        "1.0.0",
+		}},
+		Dependencies: &[]gopom.Dependency{
+			makeDep("io.netty", "netty-handler", "${netty.version}"),
+			makeDep("io.netty", "netty-buffer", "4.1.94.Final"),
+		},
+		DependencyManagement: &gopom.DependencyManagement{Dependencies: &[]gopom.Dependency{
+			makeDep("org.slf4j", "slf4j-api", "${slf4j.version}"),
+		}},
+	}
+	testCases := []struct {
+		name            string
+		propertyPatches map[string]string
+		want            map[string]bool
+	}{{
+		name: "through other properties",
+		want: map[string]bool{"netty.version": true, "netty.major": true, "slf4j.version": true},
+	}, {
+		name:            "patched value",
+		propertyPatches: map[string]string{"netty.version": "${netty.full}"},
+		want:            map[string]bool{"netty.version": true, "netty.full": true, "slf4j.version": true},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, dependencyProperties(project, tc.propertyPatches)); diff != "" {
+				t.Errorf("dependencyProperties() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestPatchesFromPomFiles(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -72,9 +411,9 @@ func TestPatchesFromPomFiles(t *testing.T) {
 		// DependencyManagement.dependencies.
 		name:    "trino - dependency patch - add new ones and replace existing",
 		in:      "trino.pom.xml",
-		patches: []Patch{{GroupID: "io.projectreactor.netty", ArtifactID: "reactor-netty-http", Version: "1.0.39", Scope: "import"}, {GroupID: "org.json", ArtifactID: "json", Version: "20231013"}, {ArtifactID: "ch.qos.logback", GroupID: "logback-core", Version: "[1.4.12,2.0.0)"}, {GroupID: "com.azure", ArtifactID: "azure-sdk-bom", Version: "1.2.19", Type: "pom", Scope: "INVALID"}},
+		patches: []Patch{{GroupID: "io.projectreactor.netty", ArtifactID: "reactor-netty-http", Version: "1.0.39", Scope: "import"}, {GroupID: "org.json", ArtifactID: "json", Version: "20231013"}, {GroupID: "ch.qos.logback", ArtifactID: "logback-core", Version: "[1.4.12,2.0.0)"}, {GroupID: "com.azure", ArtifactID: "azure-sdk-bom", Version: "1.2.19", Type: "pom", Scope: "INVALID"}},
 
-		wantDMDeps: []Patch{{GroupID: "io.projectreactor.netty", ArtifactID: "reactor-netty-http", Version: "1.0.39", Scope: "import"}, {GroupID: "org.json", ArtifactID: "json", Version: "20231013"}, {ArtifactID: "ch.qos.logback", GroupID: "logback-core", Version: "[1.4.12,2.0.0)"}, {GroupID: "com.azure", ArtifactID: "azure-sdk-bom", Version: "1.2.19", Type: "pom", Scope: "import"}},
+		wantDMDeps: []Patch{{GroupID: "io.projectreactor.netty", ArtifactID: "reactor-netty-http", Version: "1.0.39", Scope: "import"}, {GroupID: "org.json", ArtifactID: "json", Version: "20231013"}, {GroupID: "ch.qos.logback", ArtifactID: "logback-core", Version: "[1.4.12,2.0.0)"}, {GroupID: "com.azure", ArtifactID: "azure-sdk-bom", Version: "1.2.19", Type: "pom", Scope: "import"}},
 	}, {
 		// This patches existing dependencies in a project, but they are
 		// specified in the 'properties' section.
@@ -94,6 +433,21 @@ func TestPatchesFromPomFiles(t *testing.T) {
 		in:         "common-docker.pom.xml",
 		patches:    []Patch{{GroupID: "org.bitbucket.b_c", ArtifactID: "jose4j", Version: "0.9.6"}},
 		wantDMDeps: []Patch{{GroupID: "org.bitbucket.b_c", ArtifactID: "jose4j", Version: "0.9.6"}},
+	}, {
+		// Aggregators (type pom without scope import) keep their scope
+		// and type, even when the patch looks like a BOM import.
+		name: "aggregator - dependency patch - keeps scope and type",
+		in:   "aggregator.pom.xml",
+		patches: []Patch{
+			{GroupID: "org.apache.tika", ArtifactID: "tika-parsers-standard-package", Version: "2.9.2", Scope: "import", Type: "pom"},
+			{GroupID: "org.apache.tika", ArtifactID: "tika-parsers", Version: "2.9.2", Scope: "import", Type: "pom"},
+			{GroupID: "io.netty", ArtifactID: "netty-bom", Version: "4.1.118.Final", Scope: "import", Type: "pom"},
+		},
+		wantDeps: []Patch{{GroupID: "org.apache.tika", ArtifactID: "tika-parsers-standard-package", Version: "2.9.2", Scope: "compile", Type: "pom"}},
+		wantDMDeps: []Patch{
+			{GroupID: "org.apache.tika", ArtifactID: "tika-parsers", Version: "2.9.2", Type: "pom"},
+			{GroupID: "io.netty", ArtifactID: "netty-bom", Version: "4.1.118.Final", Scope: "import", Type: "pom"},
+		},
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -242,6 +596,75 @@ func TestParsePatches(t *testing.T) {
 			Scope:      "import", // defaulted
 			Type:       "jar",    // defaulted
 		}},
+	}, {
+		name:   "file - rename",
+		inFile: "testdata/rename-patches.yaml",
+		want: []Patch{{
+			GroupID:    "javax.servlet",
+			ArtifactID: "javax.servlet-api",
+			Version:    "6.0.0",
+			Scope:      "import", // defaulted
+			Type:       "jar",    // defaulted
+			RenameTo:   &Coordinate{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api"},
+		}},
+	}, {
+		name:   "file - multiple documents",
+		inFile: "testdata/multi-doc-patches.yaml",
+		want: []Patch{{
+			GroupID:    "io.projectreactor.netty",
+			ArtifactID: "reactor-netty-http",
+			Version:    "1.0.39",
+			Scope:      "import", // defaulted
+			Type:       "jar",    // defaulted
+		}, {
+			GroupID:    "org.json",
+			ArtifactID: "json",
+			Version:    "20231013",
+			Scope:      "import", // defaulted
+			Type:       "jar",    // defaulted
+		}, {
+			GroupID:    "ch.qos.logback",
+			ArtifactID: "logback-core",
+			Version:    "[1.4.12,2.0.0)",
+			Scope:      "import", // defaulted
+			Type:       "pom",
+		}},
+	}, {
+		name:   "file - reason",
+		inFile: "testdata/reason-patches.yaml",
+		want: []Patch{{
+			GroupID:    "io.netty",
+			ArtifactID: "netty-handler",
+			Version:    "4.1.118.Final",
+			Scope:      "import", // defaulted
+			Type:       "jar",    // defaulted
+			Reason:     "CVE-2025-24970",
+		}, {
+			GroupID:    "org.json",
+			ArtifactID: "json",
+			Version:    "20231013",
+			Scope:      "import", // defaulted
+			Type:       "jar",    // defaulted
+		}},
+	}, {
+		name:    "file - rename missing artifactId",
+		inFile:  "testdata/invalid-rename-patches.yaml",
+		wantErr: true,
+	}, {
+		name:   "flag - from",
+		inDeps: "io.netty@*@4.1.118.Final from:4.1.94.Final",
+		want: []Patch{{
+			GroupID:    "io.netty",
+			ArtifactID: "*",
+			Version:    "4.1.118.Final",
+			Scope:      "import", // default
+			Type:       "jar",    // default
+			From:       "4.1.94.Final",
+		}},
+	}, {
+		name:    "flag - from without a dependency",
+		inDeps:  "from:4.1.94.Final io.netty@*@4.1.118.Final",
+		wantErr: true,
 	}, {
 		name:    "invalid flag",
 		inDeps:  "g1@a1 g2",
@@ -269,6 +692,32 @@ func TestParsePatches(t *testing.T) {
 			Scope:      "import", // default
 			Type:       "jar",    // default
 		}},
+	}, {
+		name:   "flag - gradle style",
+		inDeps: "io.netty:netty-handler:4.1.118.Final g1:a1:v1:with-colon g2@a2@v2",
+		want: []Patch{{
+			GroupID:    "io.netty",
+			ArtifactID: "netty-handler",
+			Version:    "4.1.118.Final",
+			Scope:      "import", // default
+			Type:       "jar",    // default
+		}, {
+			GroupID:    "g1",
+			ArtifactID: "a1",
+			Version:    "v1:with-colon",
+			Scope:      "import", // default
+			Type:       "jar",    // default
+		}, {
+			GroupID:    "g2",
+			ArtifactID: "a2",
+			Version:    "v2",
+			Scope:      "import", // default
+			Type:       "jar",    // default
+		}},
+	}, {
+		name:    "invalid flag - gradle style",
+		inDeps:  "io.netty:netty-handler",
+		wantErr: true,
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -284,6 +733,67 @@ func TestParsePatches(t *testing.T) {
 	}
 }
 
+func TestScopeTypeMismatch(t *testing.T) {
+	testCases := []struct {
+		name  string
+		dep   gopom.Dependency
+		patch Patch
+		want  []string
+	}{{
+		name:  "defaults",
+		dep:   gopom.Dependency{Scope: "test", Type: "pom"},
+		patch: Patch{Scope: defaultScope, Type: defaultType},
+	}, {
+		name:  "same",
+		dep:   gopom.Dependency{Scope: "test", Type: "test-jar"},
+		patch: Patch{Scope: "test", Type: "test-jar"},
+	}, {
+		name:  "unset on the dependency",
+		dep:   gopom.Dependency{},
+		patch: Patch{Scope: "compile", Type: "jar"},
+	}, {
+		name:  "different",
+		dep:   gopom.Dependency{Scope: "test"},
+		patch: Patch{Scope: "runtime", Type: "pom"},
+		want:  []string{"scope runtime but the dependency has scope test", "type pom but the dependency has type jar"},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, scopeTypeMismatch(tc.dep, tc.patch)); diff != "" {
+				t.Errorf("%s: scopeTypeMismatch() (-want +got)\n%s", tc.name, diff)
+			}
+		})
+	}
+}
+
+func TestLooksTransposed(t *testing.T) {
+	testCases := []struct {
+		name  string
+		patch Patch
+		want  bool
+	}{{
+		name:  "proper coordinates",
+		patch: Patch{GroupID: "ch.qos.logback", ArtifactID: "logback-core"},
+	}, {
+		name:  "no dots anywhere",
+		patch: Patch{GroupID: "junit", ArtifactID: "junit"},
+	}, {
+		name:  "dots in both",
+		patch: Patch{GroupID: "org.apache.commons", ArtifactID: "commons.lang"},
+	}, {
+		name:  "swapped",
+		patch: Patch{GroupID: "logback-core", ArtifactID: "ch.qos.logback"},
+		want:  true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := looksTransposed(tc.patch); got != tc.want {
+				t.Errorf("%s: looksTransposed(%+v) = %v, want %v", tc.name, tc.patch, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestParseProperties(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -308,6 +818,14 @@ func TestParseProperties(t *testing.T) {
 			"prop2": "value2",
 			"prop1": "value1",
 		},
+	}, {
+		// The later documents win.
+		name:   "file - multiple documents",
+		inFile: "testdata/multi-doc-properties.yaml",
+		want: map[string]string{
+			"prop2": "value2",
+			"prop1": "value1",
+		},
 	}, {
 		name:    "flag",
 		inFile:  "",
@@ -334,3 +852,36 @@ func TestParseProperties(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchUnreferencedPropertyWarning(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       *gopom.Project
+		wantWarn bool
+	}{{
+		name:     "not referenced",
+		in:       &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"k": "1.0.0"}, Order: []string{"k"}}, Dependencies: &[]gopom.Dependency{makeDep("a", "b", "1.0.0")}},
+		wantWarn: true,
+	}, {
+		name: "referenced by a dependency",
+		in:   &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"k": "1.0.0"}, Order: []string{"k"}}, Dependencies: &[]gopom.Dependency{makeDep("a", "b", "${k}")}},
+	}, {
+		name: "referenced by another property",
+		in:   &gopom.Project{Properties: &gopom.Properties{Entries: map[string]string{"k": "1.0.0", "other": "v${k}"}, Order: []string{"k", "other"}}},
+	}, {
+		name: "modules",
+		in:   &gopom.Project{Modules: &[]string{"app"}, Properties: &gopom.Properties{Entries: map[string]string{"k": "1.0.0"}, Order: []string{"k"}}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+			if _, err := PatchProject(ctx, tc.in, nil, map[string]string{"k": "1.0.1"}); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(buf.String(), "not referenced anywhere"); got != tc.wantWarn {
+				t.Errorf("%s: warned = %v, want %v: %s", tc.name, got, tc.wantWarn, buf.String())
+			}
+		})
+	}
+}
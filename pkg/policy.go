@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// Policy is the format of a policy file: dependencies that must not be used,
+// and the minimum versions of the ones that may.
+type Policy struct {
+	Banned   []BannedDependency `json:"banned,omitempty" yaml:"banned,omitempty"`
+	Minimums []Constraint       `json:"minimums,omitempty" yaml:"minimums,omitempty"`
+}
+
+// BannedDependency is a dependency that must not be used, and why.
+type BannedDependency struct {
+	GroupID    string `json:"groupId" yaml:"groupId"`
+	ArtifactID string `json:"artifactId" yaml:"artifactId"`
+	Reason     string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ViolationKind is the kind of policy Violation.
+type ViolationKind string
+
+const (
+	ViolationBanned       ViolationKind = "banned"
+	ViolationBelowMinimum ViolationKind = "below-minimum"
+)
+
+// Violation is a dependency in a project that does not comply with a Policy.
+type Violation struct {
+	Kind       ViolationKind `json:"kind"`
+	GroupID    string        `json:"groupId"`
+	ArtifactID string        `json:"artifactId"`
+	Version    string        `json:"version,omitempty"`
+	// Minimum is the required version for ViolationBelowMinimum.
+	Minimum string `json:"minimum,omitempty"`
+	// Reason is why the dependency is banned for ViolationBanned.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ParsePolicy reads a policy file.
+func ParsePolicy(policyFile string) (*Policy, error) {
+	var policy Policy
+	if err := decodeFile(policyFile, &policy); err != nil {
+		return nil, err
+	}
+	for _, b := range policy.Banned {
+		if b.GroupID == "" || b.ArtifactID == "" {
+			return nil, fmt.Errorf("invalid banned dependency %s:%s, groupId and artifactId are required", b.GroupID, b.ArtifactID)
+		}
+	}
+	for _, m := range policy.Minimums {
+		if m.GroupID == "" || m.ArtifactID == "" || m.Version == "" {
+			return nil, fmt.Errorf("invalid minimum %s:%s:%s, groupId, artifactId and version are required", m.GroupID, m.ArtifactID, m.Version)
+		}
+	}
+	return &policy, nil
+}
+
+// CheckPolicy returns the dependencies of the project at path that violate
+// the policy, sorted by groupId and artifactId. Versions are resolved through
+// the project's EffectiveProperties. Versions that can not be resolved or
+// compared to the minimum are logged and not reported as violations.
+func CheckPolicy(ctx context.Context, path string, project *gopom.Project, policy *Policy) []Violation {
+	log := clog.FromContext(ctx)
+
+	banned := make(map[string]BannedDependency, len(policy.Banned))
+	for _, b := range policy.Banned {
+		banned[b.GroupID+":"+b.ArtifactID] = b
+	}
+	minimums := make(map[string]Constraint, len(policy.Minimums))
+	for _, m := range policy.Minimums {
+		minimums[m.GroupID+":"+m.ArtifactID] = m
+	}
+	props := EffectiveProperties(ctx, path, project)
+
+	violations := []Violation{}
+	seen := map[string]bool{}
+	for _, dep := range allDependencies(project) {
+		key := dep.GroupID + ":" + dep.ArtifactID
+		version, resolved := resolveVersion(dep.Version, props)
+		if b, ok := banned[key]; ok && !seen[key+"@banned"] {
+			seen[key+"@banned"] = true
+			violations = append(violations, Violation{Kind: ViolationBanned, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: version, Reason: b.Reason})
+		}
+		m, ok := minimums[key]
+		if !ok || dep.Version == "" {
+			continue
+		}
+		if !resolved {
+			log.Warnf("Can not resolve %s version %s, not checking it against the minimum %s", key, dep.Version, m.Version)
+			continue
+		}
+		cmp, ok := compareVersions(version, m.Version)
+		if !ok {
+			log.Warnf("Can not compare %s version %s to the minimum %s", key, version, m.Version)
+			continue
+		}
+		if cmp < 0 && !seen[key+"@"+version] {
+			seen[key+"@"+version] = true
+			violations = append(violations, Violation{Kind: ViolationBelowMinimum, GroupID: dep.GroupID, ArtifactID: dep.ArtifactID, Version: version, Minimum: m.Version})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].GroupID != violations[j].GroupID {
+			return violations[i].GroupID < violations[j].GroupID
+		}
+		return violations[i].ArtifactID < violations[j].ArtifactID
+	})
+	return violations
+}
@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePolicy(t *testing.T) {
+	got, err := ParsePolicy("testdata/policy.yaml")
+	if err != nil {
+		t.Fatalf("ParsePolicy() = %v", err)
+	}
+	if len(got.Banned) != 2 || len(got.Minimums) != 4 {
+		t.Errorf("ParsePolicy() got %d banned and %d minimums, want 2 and 4", len(got.Banned), len(got.Minimums))
+	}
+	if _, err := ParsePolicy("testdata/invalid-policy.yaml"); err == nil {
+		t.Errorf("ParsePolicy() with a missing version did not fail")
+	}
+	if _, err := ParsePolicy("testdata/missing"); err == nil {
+		t.Errorf("ParsePolicy() with a missing file did not fail")
+	}
+}
+
+func TestCheckPolicy(t *testing.T) {
+	policy, err := ParsePolicy("testdata/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "testdata/cloudwatch-exporter.pom.xml"
+	parsedPom, err := gopom.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Violation{
+		{Kind: ViolationBelowMinimum, GroupID: "com.github.ben-manes.caffeine", ArtifactID: "caffeine", Version: "3.1.1", Minimum: "3.1.8"},
+		{Kind: ViolationBelowMinimum, GroupID: "io.prometheus", ArtifactID: "simpleclient", Version: "0.16.0", Minimum: "0.16.1"},
+		{Kind: ViolationBanned, GroupID: "org.yaml", ArtifactID: "snakeyaml", Version: "2.0", Reason: "use jackson-dataformat-yaml instead"},
+	}
+	if diff := cmp.Diff(want, CheckPolicy(context.Background(), path, parsedPom, policy)); diff != "" {
+		t.Errorf("CheckPolicy() (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,309 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// Module is a parsed POM in a reactor.
+type Module struct {
+	Path    string
+	Project *gopom.Project
+}
+
+// skipDirs are not walked when looking for modules.
+var skipDirs = map[string]bool{
+	".git":         true,
+	"target":       true,
+	"node_modules": true,
+}
+
+// FindModules walks root and parses every pom.xml under it. Files that can
+// not be parsed are skipped with a warning. Modules are returned sorted by
+// path.
+func FindModules(ctx context.Context, root string) ([]Module, error) {
+	log := clog.FromContext(ctx)
+
+	var modules []Module
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "pom.xml" {
+			return nil
+		}
+		project, err := gopom.Parse(path)
+		if err != nil {
+			log.Warnf("Skipping %s, failed to parse: %v", path, err)
+			return nil
+		}
+		modules = append(modules, Module{Path: path, Project: project})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Path < modules[j].Path })
+	return modules, nil
+}
+
+// parentPath returns the path of the parent POM of the project at path, if it
+// has a parent that can be found on disk through its relativePath (which
+// defaults to ../pom.xml).
+func parentPath(path string, project *gopom.Project) (string, bool) {
+	if project.Parent == nil {
+		return "", false
+	}
+	rel := project.Parent.RelativePath
+	if rel == "" {
+		rel = "../pom.xml"
+	}
+	parent := filepath.Join(filepath.Dir(path), rel)
+	if fi, err := os.Stat(parent); err == nil && fi.IsDir() {
+		parent = filepath.Join(parent, "pom.xml")
+	}
+	if _, err := os.Stat(parent); err != nil {
+		return "", false
+	}
+	return parent, true
+}
+
+// findParent returns the parent POM of the project at path, if one can be
+// found on disk through its relativePath. Like Maven, the POM found there is
+// only used if its groupId and artifactId are the ones of the project's
+// parent, otherwise, e.g. for an external parent of a module below an
+// aggregator, an error says why it is not used.
+func findParent(path string, project *gopom.Project) (Module, bool, error) {
+	parent, ok := parentPath(path, project)
+	if !ok {
+		return Module{}, false, nil
+	}
+	parsed, err := gopom.Parse(parent)
+	if err != nil {
+		return Module{}, false, fmt.Errorf("failed to parse parent %s: %w", parent, err)
+	}
+	groupID := parsed.GroupID
+	if groupID == "" && parsed.Parent != nil {
+		groupID = parsed.Parent.GroupID
+	}
+	if groupID != project.Parent.GroupID || parsed.ArtifactID != project.Parent.ArtifactID {
+		return Module{}, false, fmt.Errorf("%s is %s:%s, not the parent %s:%s", parent, groupID, parsed.ArtifactID, project.Parent.GroupID, project.Parent.ArtifactID)
+	}
+	return Module{Path: parent, Project: parsed}, true, nil
+}
+
+// InReactor reports whether the project at path is a module of a
+// multi-module build on disk: it has a parent that can be found through its
+// relativePath, and that is the parent it declares.
+func InReactor(path string, project *gopom.Project) bool {
+	_, ok, _ := findParent(path, project)
+	return ok
+}
+
+// EffectiveProperties returns the properties visible to the project at path:
+// its own, and the ones inherited from its parents that can be found on disk,
+// with the closest definition winning. The project.version, project.groupId,
+// and project.artifactId built-ins are included as well.
+func EffectiveProperties(ctx context.Context, path string, project *gopom.Project) map[string]string {
+	return chainProperties(parentChain(ctx, path, project))
+}
+
+// canonicalPath returns the absolute path of path with the symlinks resolved,
+// so that the same file is recognized whichever way it is reached.
+func canonicalPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// parentChain returns the project at path followed by its parents that can be
+// found on disk, nearest first. A POM is never in the chain twice, even when
+// it is reached again through a symlink.
+func parentChain(ctx context.Context, path string, project *gopom.Project) []Module {
+	log := clog.FromContext(ctx)
+
+	chain := []Module{{Path: path, Project: project}}
+	seen := map[string]bool{}
+	if canonical, err := canonicalPath(path); err == nil {
+		seen[canonical] = true
+	}
+	current, currentPath := project, path
+	for {
+		parent, ok, err := findParent(currentPath, current)
+		if err != nil {
+			log.Warnf("Not following the parents of %s: %v", currentPath, err)
+			break
+		}
+		if !ok {
+			break
+		}
+		canonical, err := canonicalPath(parent.Path)
+		if err != nil || seen[canonical] {
+			break
+		}
+		seen[canonical] = true
+		chain = append(chain, parent)
+		current, currentPath = parent.Project, parent.Path
+	}
+	return chain
+}
+
+// chainProperties merges the properties of a parentChain, the nearest
+// definition winning, and adds the built-ins of the first project.
+func chainProperties(chain []Module) map[string]string {
+	props := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].Project.Properties != nil {
+			for k, v := range chain[i].Project.Properties.Entries {
+				props[k] = v
+			}
+		}
+	}
+
+	project := chain[0].Project
+	props["project.groupId"] = project.GroupID
+	props["project.artifactId"] = project.ArtifactID
+	props["project.version"] = project.Version
+	if project.Parent != nil {
+		if project.GroupID == "" {
+			props["project.groupId"] = project.Parent.GroupID
+		}
+		if project.Version == "" {
+			props["project.version"] = project.Parent.Version
+		}
+	}
+	return props
+}
+
+var propertyRefRe = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// maxResolveDepth bounds how many levels of properties referencing
+// properties are followed.
+const maxResolveDepth = 10
+
+// resolveVersion replaces the property references in version with their
+// values. Returns false if any reference can not be resolved.
+func resolveVersion(version string, props map[string]string) (string, bool) {
+	for i := 0; i < maxResolveDepth && propertyRefRe.MatchString(version); i++ {
+		resolved := true
+		version = propertyRefRe.ReplaceAllStringFunc(version, func(ref string) string {
+			v, ok := props[propertyRefRe.FindStringSubmatch(ref)[1]]
+			if !ok {
+				resolved = false
+				return ref
+			}
+			return v
+		})
+		if !resolved {
+			return version, false
+		}
+	}
+	return version, !propertyRefRe.MatchString(version)
+}
+
+// referencedProperties returns the properties that value references, directly
+// or through the values of other properties in props.
+func referencedProperties(value string, props map[string]string) map[string]bool {
+	referenced := map[string]bool{}
+	var visit func(v string)
+	visit = func(v string) {
+		for _, m := range propertyRefRe.FindAllStringSubmatch(v, -1) {
+			if !referenced[m[1]] {
+				referenced[m[1]] = true
+				visit(props[m[1]])
+			}
+		}
+	}
+	visit(value)
+	return referenced
+}
+
+// propertyCycles returns the properties that reference each other in a cycle,
+// e.g. a=${b} and b=${a}, which can never be resolved. Each cycle starts with
+// its smallest name, and the cycles are sorted.
+func propertyCycles(props map[string]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	seen := map[string]bool{}
+	var cycles [][]string
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, m := range propertyRefRe.FindAllStringSubmatch(props[name], -1) {
+			ref := m[1]
+			if _, ok := props[ref]; !ok {
+				continue
+			}
+			switch state[ref] {
+			case unvisited:
+				visit(ref)
+			case visiting:
+				var cycle []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == ref {
+						cycle = append([]string{}, stack[i:]...)
+						break
+					}
+				}
+				// Rotate so that the same cycle found from another property
+				// is recognized.
+				smallest := 0
+				for i := range cycle {
+					if cycle[i] < cycle[smallest] {
+						smallest = i
+					}
+				}
+				cycle = append(cycle[smallest:], cycle[:smallest]...)
+				if key := strings.Join(cycle, " "); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return strings.Join(cycles[i], " ") < strings.Join(cycles[j], " ") })
+	return cycles
+}
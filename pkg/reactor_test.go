@@ -0,0 +1,213 @@
+package pkg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindModules(t *testing.T) {
+	modules, err := FindModules(context.Background(), "testdata/reactor")
+	if err != nil {
+		t.Fatalf("FindModules() = %v", err)
+	}
+	var got []string
+	for _, m := range modules {
+		got = append(got, m.Path)
+	}
+	// target is skipped.
+	want := []string{
+		filepath.Join("testdata", "reactor", "module-a", "pom.xml"),
+		filepath.Join("testdata", "reactor", "module-b", "pom.xml"),
+		filepath.Join("testdata", "reactor", "module-c", "pom.xml"),
+		filepath.Join("testdata", "reactor", "pom.xml"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FindModules() (-want +got)\n%s", diff)
+	}
+}
+
+func TestEffectiveProperties(t *testing.T) {
+	modules, err := FindModules(context.Background(), "testdata/reactor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// module-c overrides jackson.version, and inherits netty.version.
+	got := EffectiveProperties(context.Background(), modules[2].Path, modules[2].Project)
+	want := map[string]string{
+		"netty.version":      "4.1.118.Final",
+		"jackson.version":    "2.17.2",
+		"project.groupId":    "dev.chainguard.reactor",
+		"project.artifactId": "module-c",
+		"project.version":    "1.0.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EffectiveProperties() (-want +got)\n%s", diff)
+	}
+}
+
+func TestEffectivePropertiesExternalParent(t *testing.T) {
+	// The aggregator in ../pom.xml is not the parent, its properties are not
+	// inherited.
+	path := filepath.Join("testdata", "external-parent", "app", "pom.xml")
+	project, err := gopom.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"project.groupId":    "dev.chainguard.aggregator",
+		"project.artifactId": "app",
+		"project.version":    "1.0.0",
+	}
+	if diff := cmp.Diff(want, EffectiveProperties(context.Background(), path, project)); diff != "" {
+		t.Errorf("EffectiveProperties() (-want +got)\n%s", diff)
+	}
+}
+
+func TestParentChainSymlink(t *testing.T) {
+	// The root POM's parent is the module itself, through a symlink.
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join(root, "pom.xml"):           `<project><groupId>g</groupId><artifactId>root</artifactId><parent><groupId>g</groupId><artifactId>module</artifactId><relativePath>alias/pom.xml</relativePath></parent></project>`,
+		filepath.Join(root, "module", "pom.xml"): `<project><groupId>g</groupId><artifactId>module</artifactId><parent><groupId>g</groupId><artifactId>root</artifactId></parent></project>`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(root, "module"), filepath.Join(root, "alias")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	// Also reach the module with a . segment in its path.
+	path := filepath.Join(root, "module") + string(filepath.Separator) + "." + string(filepath.Separator) + "pom.xml"
+	project, err := gopom.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range parentChain(context.Background(), path, project) {
+		got = append(got, m.Project.ArtifactID)
+	}
+	if diff := cmp.Diff([]string{"module", "root"}, got); diff != "" {
+		t.Errorf("parentChain() (-want +got)\n%s", diff)
+	}
+}
+
+func TestInReactor(t *testing.T) {
+	// A single pom.xml next to another module, it has no parent on disk.
+	siblings := t.TempDir()
+	for _, path := range []string{
+		filepath.Join(siblings, "app", "pom.xml"),
+		filepath.Join(siblings, "lib", "pom.xml"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("<project></project>"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		project *gopom.Project
+		want    bool
+	}{{
+		name:    "parent on disk",
+		path:    filepath.Join("testdata", "reactor", "module-a", "pom.xml"),
+		project: &gopom.Project{Parent: &gopom.Parent{GroupID: "dev.chainguard.reactor", ArtifactID: "reactor-parent", Version: "1.0.0"}},
+		want:    true,
+	}, {
+		name:    "other parent on disk",
+		path:    filepath.Join("testdata", "external-parent", "app", "pom.xml"),
+		project: &gopom.Project{Parent: &gopom.Parent{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-parent", Version: "3.3.0"}},
+		want:    false,
+	}, {
+		name:    "sibling modules",
+		path:    filepath.Join(siblings, "app", "pom.xml"),
+		project: &gopom.Project{},
+		want:    false,
+	}, {
+		name:    "parent not on disk",
+		path:    filepath.Join(siblings, "app", "pom.xml"),
+		project: &gopom.Project{Parent: &gopom.Parent{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-parent", Version: "3.3.0"}},
+		want:    false,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := InReactor(tc.path, tc.project); got != tc.want {
+				t.Errorf("InReactor() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	props := map[string]string{"a": "1", "b": "${a}.2", "netty.major": "4", "netty.minor": "1", "cycle.a": "${cycle.b}", "cycle.b": "${cycle.a}"}
+	testCases := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{in: "1.0.0", want: "1.0.0", wantOK: true},
+		{in: "${a}", want: "1", wantOK: true},
+		{in: "${b}", want: "1.2", wantOK: true},
+		{in: "${netty.major}.${netty.minor}.Final", want: "4.1.Final", wantOK: true},
+		{in: "${missing}", want: "${missing}"},
+		// Cycles are given up on instead of looping forever.
+		{in: "${cycle.a}", want: "${cycle.a}"},
+	}
+	for _, tc := range testCases {
+		got, ok := resolveVersion(tc.in, props)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("resolveVersion(%s) = %s, %v, want %s, %v", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestReferencedProperties(t *testing.T) {
+	props := map[string]string{"a": "${b}.${c}", "b": "${a}", "c": "1", "d": "${c}"}
+	want := map[string]bool{"a": true, "b": true, "c": true, "undefined": true}
+	if diff := cmp.Diff(want, referencedProperties("${a}-${undefined}", props)); diff != "" {
+		t.Errorf("referencedProperties() (-want +got)\n%s", diff)
+	}
+}
+
+func TestPropertyCycles(t *testing.T) {
+	testCases := []struct {
+		name  string
+		props map[string]string
+		want  [][]string
+	}{{
+		name:  "no cycles",
+		props: map[string]string{"a": "${b}", "b": "${c}.${c}", "c": "1", "d": "${undefined}"},
+	}, {
+		name:  "self reference",
+		props: map[string]string{"a": "${a}"},
+		want:  [][]string{{"a"}},
+	}, {
+		name:  "two cycles",
+		props: map[string]string{"a": "${b}", "b": "${c}", "c": "${a}-${d}", "d": "1", "x": "${y}", "y": "${x}", "z": "${x}"},
+		want:  [][]string{{"a", "b", "c"}, {"x", "y"}},
+	}, {
+		name:  "cycle entered from the middle",
+		props: map[string]string{"a": "${c}", "b": "${d}", "c": "${b}", "d": "${c}"},
+		want:  [][]string{{"b", "d", "c"}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, propertyCycles(tc.props)); diff != "" {
+				t.Errorf("%s: propertyCycles() (-want +got)\n%s", tc.name, diff)
+			}
+		})
+	}
+}
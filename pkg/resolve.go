@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/chainguard-dev/gopom"
+)
+
+// VersionSource is where the effective version of a dependency comes from.
+type VersionSource string
+
+const (
+	// SourceInline is a literal version on the dependency.
+	SourceInline VersionSource = "inline"
+	// SourceProperty is a version that references a property.
+	SourceProperty VersionSource = "property"
+	// SourceUnresolved is a version that can not be resolved from the POMs on
+	// disk, e.g. because it is managed by or uses a property of an external
+	// parent or BOM.
+	SourceUnresolved VersionSource = "unresolved"
+)
+
+// Resolution is the effective version of a dependency and where it comes
+// from.
+type Resolution struct {
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	// Declared is the version as written, and DeclaredIn the POM it is
+	// written in. For managed dependencies that can be a parent.
+	Declared   string `json:"declared,omitempty"`
+	DeclaredIn string `json:"declaredIn,omitempty"`
+	// Version is the effective version, if it could be resolved.
+	Version string        `json:"version,omitempty"`
+	Source  VersionSource `json:"source"`
+	// Property is the property the declared version references, and
+	// PropertyIn the POM that defines it.
+	Property   string `json:"property,omitempty"`
+	PropertyIn string `json:"propertyIn,omitempty"`
+}
+
+// Resolve returns the effective version of groupID:artifactID in the project
+// at path. If searchParents is set, versions and properties inherited from
+// the parents that can be found on disk are followed too. Returns an error if
+// the project does not declare the dependency at all.
+func Resolve(ctx context.Context, path string, project *gopom.Project, groupID, artifactID string, searchParents bool) (*Resolution, error) {
+	chain := []Module{{Path: path, Project: project}}
+	if searchParents {
+		chain = parentChain(ctx, path, project)
+	}
+
+	d, found := findDeclaration(chain, groupID, artifactID)
+	if !found {
+		return nil, fmt.Errorf("%s:%s is not declared in %s", groupID, artifactID, path)
+	}
+	r := &Resolution{GroupID: groupID, ArtifactID: artifactID, Source: SourceUnresolved, Declared: d.version, DeclaredIn: d.versionIn}
+	if r.Declared == "" {
+		// Managed somewhere we can not see.
+		return r, nil
+	}
+
+	if match := propertyRefRe.FindStringSubmatch(r.Declared); match != nil {
+		r.Property = match[1]
+		r.PropertyIn = propertyIn(chain, r.Property)
+	}
+	props := chainProperties(chain)
+	version, ok := resolveVersion(r.Declared, props)
+	if !ok {
+		// Only the cycles on the way to this version, not every one in
+		// the chain.
+		reachable := referencedProperties(r.Declared, props)
+		for _, cycle := range propertyCycles(props) {
+			if !reachable[cycle[0]] {
+				continue
+			}
+			clog.FromContext(ctx).Warnf("Properties %s reference each other in a cycle and can not be resolved", strings.Join(append(cycle, cycle[0]), " -> "))
+		}
+		return r, nil
+	}
+	r.Version = version
+	r.Source = SourceInline
+	if r.Property != "" {
+		r.Source = SourceProperty
+	}
+	return r, nil
+}
+
+// declaration is where a dependency and its version are declared in a
+// parentChain.
+type declaration struct {
+	// dependency is the version of the nearest declaration in dependencies
+	// as written, and dependencyIn the POM it is in, empty if there is none.
+	dependency   string
+	dependencyIn string
+	// version is the version as written, and versionIn the POM that gives
+	// it: the declaration in dependencies if it has a version, the nearest
+	// dependencyManagement entry with one otherwise. Empty if nothing on
+	// disk gives a version.
+	version   string
+	versionIn string
+	// managed is true if the version comes from dependencyManagement.
+	managed bool
+}
+
+// findDeclaration returns the declaration of groupID:artifactID in chain. Like
+// Maven, the version of a dependency declared in dependencies wins over any
+// managed version, and nearer POMs win over their parents. Returns false if
+// the dependency is not in any dependencies or dependencyManagement.
+func findDeclaration(chain []Module, groupID, artifactID string) (declaration, bool) {
+	matches := func(dep gopom.Dependency) bool { return dep.GroupID == groupID && dep.ArtifactID == artifactID }
+
+	var d declaration
+	found := false
+dependencies:
+	for _, m := range chain {
+		for _, dep := range dependencies(m.Project.Dependencies) {
+			if matches(dep) {
+				found = true
+				d.dependency, d.dependencyIn = dep.Version, m.Path
+				if dep.Version != "" {
+					d.version, d.versionIn = dep.Version, m.Path
+				}
+				break dependencies
+			}
+		}
+	}
+	for _, m := range chain {
+		for _, dep := range managedDependencies(m.Project) {
+			if !matches(dep) {
+				continue
+			}
+			found = true
+			if d.version == "" && dep.Version != "" {
+				d.version, d.versionIn, d.managed = dep.Version, m.Path, true
+			}
+		}
+	}
+	return d, found
+}
+
+// propertyIn returns the nearest POM of chain that defines the property, empty
+// if none does, e.g. for built-ins.
+func propertyIn(chain []Module, name string) string {
+	for _, m := range chain {
+		if m.Project.Properties == nil {
+			continue
+		}
+		if _, ok := m.Project.Properties.Entries[name]; ok {
+			return m.Path
+		}
+	}
+	return ""
+}
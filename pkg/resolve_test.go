@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolve(t *testing.T) {
+	moduleA := filepath.Join("testdata", "reactor", "module-a", "pom.xml")
+	moduleB := filepath.Join("testdata", "reactor", "module-b", "pom.xml")
+	parent := filepath.Join("testdata", "reactor", "module-a", "..", "pom.xml")
+	testCases := []struct {
+		name          string
+		path          string
+		groupID       string
+		artifactID    string
+		searchParents bool
+		want          *Resolution
+		wantErr       bool
+	}{{
+		name:       "inline",
+		path:       moduleB,
+		groupID:    "io.netty",
+		artifactID: "netty-handler",
+		want:       &Resolution{GroupID: "io.netty", ArtifactID: "netty-handler", Declared: "4.1.94.Final", DeclaredIn: moduleB, Version: "4.1.94.Final", Source: SourceInline},
+	}, {
+		name:          "property in the parent",
+		path:          moduleA,
+		groupID:       "io.netty",
+		artifactID:    "netty-handler",
+		searchParents: true,
+		want:          &Resolution{GroupID: "io.netty", ArtifactID: "netty-handler", Declared: "${netty.version}", DeclaredIn: moduleA, Version: "4.1.118.Final", Source: SourceProperty, Property: "netty.version", PropertyIn: parent},
+	}, {
+		name:       "property in the parent, not searched",
+		path:       moduleA,
+		groupID:    "io.netty",
+		artifactID: "netty-handler",
+		want:       &Resolution{GroupID: "io.netty", ArtifactID: "netty-handler", Declared: "${netty.version}", DeclaredIn: moduleA, Source: SourceUnresolved, Property: "netty.version"},
+	}, {
+		name:          "managed in the parent",
+		path:          moduleA,
+		groupID:       "com.fasterxml.jackson.core",
+		artifactID:    "jackson-databind",
+		searchParents: true,
+		want:          &Resolution{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Declared: "${jackson.version}", DeclaredIn: parent, Version: "2.18.0", Source: SourceProperty, Property: "jackson.version", PropertyIn: parent},
+	}, {
+		name:       "built-in property",
+		path:       moduleA,
+		groupID:    "dev.chainguard.reactor",
+		artifactID: "module-b",
+		want:       &Resolution{GroupID: "dev.chainguard.reactor", ArtifactID: "module-b", Declared: "${project.version}", DeclaredIn: moduleA, Version: "1.0.0", Source: SourceProperty, Property: "project.version"},
+	}, {
+		name:       "not declared",
+		path:       moduleB,
+		groupID:    "org.json",
+		artifactID: "nope",
+		wantErr:    true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			project, err := gopom.Parse(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Resolve(context.Background(), tc.path, project, tc.groupID, tc.artifactID, tc.searchParents)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Resolve() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Resolve() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveAgreesWithBlame(t *testing.T) {
+	// The parent declares the dependency with a version, which wins over the
+	// version the module manages.
+	root := t.TempDir()
+	parent := filepath.Join(root, "pom.xml")
+	module := filepath.Join(root, "app", "pom.xml")
+	for path, content := range map[string]string{
+		parent: `<project><groupId>g</groupId><artifactId>parent</artifactId><version>1</version>
+<dependencies><dependency><groupId>io.netty</groupId><artifactId>netty-handler</artifactId><version>4.1.118.Final</version></dependency></dependencies></project>`,
+		module: `<project><parent><groupId>g</groupId><artifactId>parent</artifactId><version>1</version></parent><artifactId>app</artifactId>
+<dependencyManagement><dependencies><dependency><groupId>io.netty</groupId><artifactId>netty-handler</artifactId><version>4.1.94.Final</version></dependency></dependencies></dependencyManagement></project>`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project, err := gopom.Parse(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Resolve(context.Background(), module, project, "io.netty", "netty-handler", true)
+	if err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+	want := &Resolution{GroupID: "io.netty", ArtifactID: "netty-handler", Declared: "4.1.118.Final", DeclaredIn: filepath.Join(root, "app", "..", "pom.xml"), Version: "4.1.118.Final", Source: SourceInline}
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Resolve() (-want +got)\n%s", diff)
+	}
+	b, err := BlameDependencyVersion(context.Background(), module, project, "io.netty", "netty-handler", true)
+	if err != nil {
+		t.Fatalf("BlameDependencyVersion() = %v", err)
+	}
+	if b.Version != r.Version || b.Steps[0].File != r.DeclaredIn {
+		t.Errorf("BlameDependencyVersion() = %s from %s, Resolve() = %s from %s", b.Version, b.Steps[0].File, r.Version, r.DeclaredIn)
+	}
+}
+
+func TestResolveCycleWarnings(t *testing.T) {
+	project := &gopom.Project{
+		Properties: &gopom.Properties{Entries: map[string]string{"a.version": "${b.version}", "b.version": "${a.version}", "x": "${y}", "y": "${x}"}},
+		Dependencies: &[]gopom.Dependency{
+			{GroupID: "org.a", ArtifactID: "a", Version: "${a.version}"},
+			{GroupID: "org.u", ArtifactID: "u", Version: "${undefined.version}"},
+		},
+	}
+	testCases := []struct {
+		name       string
+		artifactID string
+		want       []string
+	}{{
+		name:       "cycle on the way",
+		artifactID: "a",
+		want:       []string{"a.version -> b.version -> a.version"},
+	}, {
+		name:       "undefined property",
+		artifactID: "u",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := WithLogger(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+			if _, err := Resolve(ctx, "pom.xml", project, "org."+tc.artifactID, tc.artifactID, false); err != nil {
+				t.Fatalf("Resolve() = %v", err)
+			}
+			if got := strings.Count(buf.String(), "cycle"); got != len(tc.want) {
+				t.Errorf("Resolve() logged %d cycles, want %d: %s", got, len(tc.want), buf.String())
+			}
+			for _, w := range tc.want {
+				if !strings.Contains(buf.String(), w) {
+					t.Errorf("Resolve() did not warn about %s, got: %s", w, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+// VerifyRoundTrip parses out, the marshaled form of project, and checks that
+// it has the same content as project. This catches anything the marshaler
+// silently drops. Returns an error describing the first difference.
+func VerifyRoundTrip(project *gopom.Project, out []byte) error {
+	var parsed gopom.Project
+	if err := xml.Unmarshal(out, &parsed); err != nil {
+		return fmt.Errorf("failed to parse the marshaled pom: %w", err)
+	}
+
+	want, got := normalizeNamespaces(*project), normalizeNamespaces(parsed)
+	if diff := firstDiff("Project", reflect.ValueOf(want), reflect.ValueOf(got)); diff != "" {
+		return fmt.Errorf("marshaled pom differs from the patched pom at %s", diff)
+	}
+	return nil
+}
+
+// normalizeNamespaces clears the namespace attributes, which Marshal moves
+// between fields.
+func normalizeNamespaces(project gopom.Project) gopom.Project {
+	project.XMLName = xml.Name{}
+	project.Xsi, project.XsiNS = "", ""
+	project.SchemaLocation, project.SchemaLocationXSI = "", ""
+	return project
+}
+
+// firstDiff walks want and got and describes the first difference, or
+// returns "" if they are equal. Nil and empty slices and maps are equal,
+// since the marshaler does not tell them apart.
+func firstDiff(path string, want, got reflect.Value) string {
+	if !want.IsValid() || !got.IsValid() {
+		if want.IsValid() == got.IsValid() {
+			return ""
+		}
+		return fmt.Sprintf("%s: %s, marshaled as %s", path, describe(want), describe(got))
+	}
+
+	switch want.Kind() {
+	case reflect.Pointer:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() == got.IsNil() {
+				return ""
+			}
+			return fmt.Sprintf("%s: %s, marshaled as %s", path, describe(want), describe(got))
+		}
+		return firstDiff(path, want.Elem(), got.Elem())
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			if !want.Type().Field(i).IsExported() {
+				continue
+			}
+			name := want.Type().Field(i).Name
+			if d := firstDiff(path+"."+name, want.Field(i), got.Field(i)); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Slice:
+		for i := 0; i < max(want.Len(), got.Len()); i++ {
+			var w, g reflect.Value
+			if i < want.Len() {
+				w = want.Index(i)
+			}
+			if i < got.Len() {
+				g = got.Index(i)
+			}
+			if d := firstDiff(fmt.Sprintf("%s[%d]", path, i), w, g); d != "" {
+				return d
+			}
+		}
+		return ""
+	case reflect.Map:
+		keys := map[string]reflect.Value{}
+		for _, k := range append(want.MapKeys(), got.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			k := keys[name]
+			if d := firstDiff(fmt.Sprintf("%s[%q]", path, name), want.MapIndex(k), got.MapIndex(k)); d != "" {
+				return d
+			}
+		}
+		return ""
+	default:
+		if reflect.DeepEqual(want.Interface(), got.Interface()) {
+			return ""
+		}
+		return fmt.Sprintf("%s: %s, marshaled as %s", path, describe(want), describe(got))
+	}
+}
+
+func describe(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<missing>"
+	}
+	return fmt.Sprintf("%q", fmt.Sprint(v.Interface()))
+}
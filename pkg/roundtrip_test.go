@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mutate  func(project *gopom.Project)
+		wantErr string
+	}{{
+		name:   "unchanged",
+		mutate: func(*gopom.Project) {},
+	}, {
+		name: "patched dependency",
+		mutate: func(project *gopom.Project) {
+			(*project.Dependencies)[0].Version = "9.9.9"
+		},
+	}, {
+		// Properties are marshaled in Order, so an entry missing from it is
+		// dropped.
+		name: "property not in order",
+		mutate: func(project *gopom.Project) {
+			project.Properties.Entries["dropped.version"] = "1.0.0"
+		},
+		wantErr: `Entries["dropped.version"]: "1.0.0", marshaled as <missing>`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			project, err := gopom.Parse("testdata/cloudwatch-exporter.pom.xml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.mutate(project)
+			out, err := project.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = VerifyRoundTrip(project, out)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("VerifyRoundTrip() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("VerifyRoundTrip() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"context"
+	"sort"
+)
+
+// CoordinateStats is how a dependency is used across the modules of a
+// reactor.
+type CoordinateStats struct {
+	GroupID    string `json:"groupId"`
+	ArtifactID string `json:"artifactId"`
+	// Modules is the number of modules that declare the dependency, with or
+	// without a version.
+	Modules int `json:"modules"`
+	// Versions are the distinct effective versions it is declared with.
+	Versions []string `json:"versions"`
+}
+
+// Stats returns the usage of every dependency across the modules, the ones
+// with the most distinct versions first, then the most used ones, then by
+// groupId and artifactId.
+func Stats(ctx context.Context, modules []Module) []CoordinateStats {
+	declaring := map[string]int{}
+	for _, m := range modules {
+		seen := map[string]bool{}
+		for _, dep := range allDependencies(m.Project) {
+			key := dep.GroupID + ":" + dep.ArtifactID
+			if !seen[key] {
+				seen[key] = true
+				declaring[key]++
+			}
+		}
+	}
+	versions := DependencyVersions(ctx, modules)
+
+	stats := make([]CoordinateStats, 0, len(declaring))
+	for key, n := range declaring {
+		c := coordinate(key)
+		distinct := distinctVersions(versions[key])
+		if distinct == nil {
+			distinct = []string{}
+		}
+		sort.Strings(distinct)
+		stats = append(stats, CoordinateStats{GroupID: c.GroupID, ArtifactID: c.ArtifactID, Modules: n, Versions: distinct})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if len(a.Versions) != len(b.Versions) {
+			return len(a.Versions) > len(b.Versions)
+		}
+		if a.Modules != b.Modules {
+			return a.Modules > b.Modules
+		}
+		if a.GroupID != b.GroupID {
+			return a.GroupID < b.GroupID
+		}
+		return a.ArtifactID < b.ArtifactID
+	})
+	return stats
+}
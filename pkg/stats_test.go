@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStats(t *testing.T) {
+	modules, err := FindModules(context.Background(), "testdata/reactor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CoordinateStats{
+		{GroupID: "com.fasterxml.jackson.core", ArtifactID: "jackson-databind", Modules: 3, Versions: []string{"2.17.2", "2.18.0"}},
+		{GroupID: "io.netty", ArtifactID: "netty-handler", Modules: 2, Versions: []string{"4.1.118.Final", "4.1.94.Final"}},
+		{GroupID: "org.json", ArtifactID: "json", Modules: 2, Versions: []string{"20231013"}},
+		{GroupID: "dev.chainguard.reactor", ArtifactID: "module-b", Modules: 1, Versions: []string{"1.0.0"}},
+	}
+	if diff := cmp.Diff(want, Stats(context.Background(), modules)); diff != "" {
+		t.Errorf("Stats() (-want +got)\n%s", diff)
+	}
+}
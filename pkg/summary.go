@@ -0,0 +1,140 @@
+package pkg
+
+// ChangeKind is the kind of change PatchProject made.
+type ChangeKind string
+
+const (
+	// ChangeUpdated is an existing dependency whose version (and possibly
+	// coordinates) changed.
+	ChangeUpdated ChangeKind = "updated"
+	// ChangeAdded is a dependency added to DependencyManagement.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved is a dependency removed from the project.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeProperty is a property that was changed or created.
+	ChangeProperty ChangeKind = "property"
+	// ChangeNoop is a patch or property that did not change anything.
+	ChangeNoop ChangeKind = "noop"
+)
+
+// Change is a single change made to a project.
+type Change struct {
+	Kind       ChangeKind `json:"kind"`
+	GroupID    string     `json:"groupId,omitempty"`
+	ArtifactID string     `json:"artifactId,omitempty"`
+	Property   string     `json:"property,omitempty"`
+	From       string     `json:"from,omitempty"`
+	To         string     `json:"to,omitempty"`
+	// RenamedFrom are the previous coordinates of a renamed dependency.
+	RenamedFrom *Coordinate `json:"renamedFrom,omitempty"`
+	// Reason is the reason of the patch that made the change.
+	Reason string `json:"reason,omitempty"`
+}
+
+// PatchSummary records the changes made to a project.
+type PatchSummary struct {
+	Changes []Change `json:"changes"`
+}
+
+// SummaryCounts are the number of changes of each kind.
+type SummaryCounts struct {
+	Updated           int `json:"updated"`
+	Added             int `json:"added"`
+	Removed           int `json:"removed"`
+	PropertiesChanged int `json:"propertiesChanged"`
+	Noop              int `json:"noop"`
+}
+
+// Record adds a change to the summary. It is safe to call on a nil summary,
+// in which case nothing is recorded.
+func (s *PatchSummary) Record(c Change) {
+	if s == nil {
+		return
+	}
+	s.Changes = append(s.Changes, c)
+}
+
+// Counts returns the number of changes of each kind.
+func (s *PatchSummary) Counts() SummaryCounts {
+	var counts SummaryCounts
+	if s == nil {
+		return counts
+	}
+	for _, c := range s.Changes {
+		switch c.Kind {
+		case ChangeUpdated:
+			counts.Updated++
+		case ChangeAdded:
+			counts.Added++
+		case ChangeRemoved:
+			counts.Removed++
+		case ChangeProperty:
+			counts.PropertiesChanged++
+		case ChangeNoop:
+			counts.Noop++
+		}
+	}
+	return counts
+}
+
+// AppliedPatches returns the dependency patches that changed the project, in
+// the --patch-file format, so that the file only has what was applied: no
+// patches that were skipped or did nothing, and the dependencies a wildcard
+// or regular expression patch matched instead of the patch itself. Renames
+// are patches of the previous coordinates with RenameTo. The scope and type
+// are the ones of the requested patch for the same coordinates, if any.
+func (s *PatchSummary) AppliedPatches(requested []Patch) []Patch {
+	if s == nil {
+		return nil
+	}
+	byKey := map[string]Patch{}
+	for _, p := range requested {
+		byKey[p.GroupID+":"+p.ArtifactID] = p
+	}
+	var applied []Patch
+	index := map[string]int{}
+	for _, c := range s.Changes {
+		if c.Kind != ChangeUpdated && c.Kind != ChangeAdded {
+			continue
+		}
+		p := Patch{GroupID: c.GroupID, ArtifactID: c.ArtifactID, Version: c.To, Scope: defaultScope, Type: defaultType, Reason: c.Reason}
+		if c.RenamedFrom != nil {
+			p.GroupID, p.ArtifactID = c.RenamedFrom.GroupID, c.RenamedFrom.ArtifactID
+			p.RenameTo = &Coordinate{GroupID: c.GroupID, ArtifactID: c.ArtifactID}
+		}
+		key := p.GroupID + ":" + p.ArtifactID
+		if r, ok := byKey[key]; ok {
+			if r.Scope != "" {
+				p.Scope = r.Scope
+			}
+			if r.Type != "" {
+				p.Type = r.Type
+			}
+		}
+		// A dependency updated in both dependencies and
+		// dependencyManagement is a single patch.
+		if i, ok := index[key]; ok {
+			applied[i] = p
+			continue
+		}
+		index[key] = len(applied)
+		applied = append(applied, p)
+	}
+	return applied
+}
+
+// AppliedProperties returns the properties that changed the project, in the
+// --properties-file format, including the ones patched through a dependency
+// patch with a property.
+func (s *PatchSummary) AppliedProperties() map[string]string {
+	if s == nil {
+		return nil
+	}
+	applied := map[string]string{}
+	for _, c := range s.Changes {
+		if c.Kind == ChangeProperty {
+			applied[c.Property] = c.To
+		}
+	}
+	return applied
+}
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chainguard-dev/gopom"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPatchSummary(t *testing.T) {
+	project := &gopom.Project{
+		Properties: &gopom.Properties{Entries: map[string]string{"p1": "1.0.0", "p2": "2.0.0"}, Order: []string{"p1", "p2"}},
+		Dependencies: &[]gopom.Dependency{
+			makeDep("a1", "b1", "1.0.0"),
+			makeDep("a2", "b2", "2.0.0"),
+			makeDep("javax.servlet", "javax.servlet-api", "4.0.1"),
+		},
+	}
+	patches := []Patch{
+		{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", Reason: "CVE-2024-0001"},
+		// Already at this version.
+		{GroupID: "a2", ArtifactID: "b2", Version: "2.0.0"},
+		{GroupID: "a3", ArtifactID: "b3", Version: "3.0.0", Scope: "import", Type: "jar", Reason: "CVE-2024-0003"},
+		{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api", Version: "6.0.0", RenameTo: &Coordinate{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api"}},
+	}
+	summary := &PatchSummary{}
+	if _, err := PatchProjectWithOptions(context.Background(), project, patches, map[string]string{"p1": "1.0.1"}, PatchOptions{Summary: summary}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Kind: ChangeUpdated, GroupID: "a1", ArtifactID: "b1", From: "1.0.0", To: "1.0.1", Reason: "CVE-2024-0001"},
+		{Kind: ChangeUpdated, GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api", From: "4.0.1", To: "6.0.0", RenamedFrom: &Coordinate{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api"}},
+		{Kind: ChangeAdded, GroupID: "a3", ArtifactID: "b3", To: "3.0.0", Reason: "CVE-2024-0003"},
+		{Kind: ChangeNoop, GroupID: "a2", ArtifactID: "b2", To: "2.0.0"},
+		{Kind: ChangeProperty, Property: "p1", From: "1.0.0", To: "1.0.1"},
+	}
+	if diff := cmp.Diff(want, summary.Changes); diff != "" {
+		t.Errorf("Changes (-want +got)\n%s", diff)
+	}
+	wantCounts := SummaryCounts{Updated: 2, Added: 1, PropertiesChanged: 1, Noop: 1}
+	if diff := cmp.Diff(wantCounts, summary.Counts()); diff != "" {
+		t.Errorf("Counts() (-want +got)\n%s", diff)
+	}
+
+	// The no-op a2:b2 is not applied.
+	wantPatches := []Patch{
+		{GroupID: "a1", ArtifactID: "b1", Version: "1.0.1", Scope: "import", Type: "jar", Reason: "CVE-2024-0001"},
+		{GroupID: "javax.servlet", ArtifactID: "javax.servlet-api", Version: "6.0.0", Scope: "import", Type: "jar", RenameTo: &Coordinate{GroupID: "jakarta.servlet", ArtifactID: "jakarta.servlet-api"}},
+		{GroupID: "a3", ArtifactID: "b3", Version: "3.0.0", Scope: "import", Type: "jar", Reason: "CVE-2024-0003"},
+	}
+	if diff := cmp.Diff(wantPatches, summary.AppliedPatches(patches)); diff != "" {
+		t.Errorf("AppliedPatches() (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"p1": "1.0.1"}, summary.AppliedProperties()); diff != "" {
+		t.Errorf("AppliedProperties() (-want +got)\n%s", diff)
+	}
+}
+
+func TestAppliedPatchesSkipped(t *testing.T) {
+	project := &gopom.Project{
+		Dependencies: &[]gopom.Dependency{
+			makeDep("org.springframework.boot", "spring-boot-starter-web", "3.3.0"),
+			makeDep("org.springframework.boot", "spring-boot-starter-json", "3.3.0"),
+			makeDep("io.netty", "netty-handler", "4.1.94.Final"),
+			makeDep("io.netty", "netty-buffer", "4.1.100.Final"),
+		},
+	}
+	patches := []Patch{
+		{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-.*", Version: "3.3.5"},
+		// Expected at another version.
+		{GroupID: "io.netty", ArtifactID: "netty-buffer", Version: "4.1.118.Final", From: "4.1.94.Final"},
+		// Not in the project.
+		{GroupID: "org.json", ArtifactID: "json", Version: "20231013"},
+		// A major bump, not allowed by the policy.
+		{GroupID: "io.netty", ArtifactID: "netty-handler", Version: "5.0.0.Alpha2"},
+	}
+	summary := &PatchSummary{}
+	opts := PatchOptions{Summary: summary, RegexMatch: true, NoAdd: true, BumpPolicy: BumpPolicyMinor}
+	if _, err := PatchProjectWithOptions(context.Background(), project, patches, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	want := []Patch{
+		{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-web", Version: "3.3.5", Scope: "import", Type: "jar"},
+		{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-json", Version: "3.3.5", Scope: "import", Type: "jar"},
+	}
+	if diff := cmp.Diff(want, summary.AppliedPatches(patches)); diff != "" {
+		t.Errorf("AppliedPatches() (-want +got)\n%s", diff)
+	}
+}
+
+func TestNilPatchSummary(t *testing.T) {
+	var summary *PatchSummary
+	summary.Record(Change{Kind: ChangeAdded})
+	if diff := cmp.Diff(SummaryCounts{}, summary.Counts()); diff != "" {
+		t.Errorf("Counts() (-want +got)\n%s", diff)
+	}
+}
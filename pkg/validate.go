@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue with an entry of a patch or properties file.
+type Problem struct {
+	// Entry is the groupId:artifactId of the patch, or the property name.
+	Entry   string `json:"entry"`
+	Message string `json:"message"`
+}
+
+// ValidatePatches checks patches, e.g. as read by ParsePatches, without a
+// POM: every patch needs coordinates and a version, and every dependency can
+// only be patched once from the same version. Patches with different from
+// versions, e.g. wildcard patches for the releases of a group, are not
+// duplicates. Problems are returned in the order of the patches.
+func ValidatePatches(patches []Patch) []Problem {
+	problems := []Problem{}
+	type patched struct{ key, from string }
+	versions := map[patched][]string{}
+	var keys []patched
+	for _, p := range patches {
+		key := p.GroupID + ":" + p.ArtifactID
+		for _, f := range []struct{ name, value string }{{"groupId", p.GroupID}, {"artifactId", p.ArtifactID}, {"version", p.Version}} {
+			switch {
+			case f.value == "":
+				problems = append(problems, Problem{Entry: key, Message: fmt.Sprintf("has no %s", f.name)})
+			case strings.ContainsAny(f.value, " \t\n"):
+				problems = append(problems, Problem{Entry: key, Message: fmt.Sprintf("%s %q contains whitespace", f.name, f.value)})
+			}
+		}
+		if isMetaVersion(p.Version) {
+			problems = append(problems, Problem{Entry: key, Message: fmt.Sprintf("uses version %s, which is deprecated and not reproducible", p.Version)})
+		}
+		if p.ArtifactID == wildcardArtifactID && p.From == "" {
+			problems = append(problems, Problem{Entry: key, Message: "matches every artifactId of the group but has no from"})
+		}
+		if p.From != "" && p.From == p.Version {
+			problems = append(problems, Problem{Entry: key, Message: fmt.Sprintf("is from %s to the same version", p.From)})
+		}
+		if p.RenameTo != nil && p.RenameTo.GroupID == p.GroupID && p.RenameTo.ArtifactID == p.ArtifactID {
+			problems = append(problems, Problem{Entry: key, Message: "is renamed to itself"})
+		}
+		k := patched{key: key, from: p.From}
+		if _, ok := versions[k]; !ok {
+			keys = append(keys, k)
+		}
+		versions[k] = append(versions[k], p.Version)
+	}
+
+	for _, k := range keys {
+		vs := versions[k]
+		if len(vs) < 2 {
+			continue
+		}
+		times := fmt.Sprintf("is patched %d times", len(vs))
+		if k.from != "" {
+			times += " from " + k.from
+		}
+		if distinct := distinctStrings(vs); len(distinct) > 1 {
+			problems = append(problems, Problem{Entry: k.key, Message: fmt.Sprintf("%s with conflicting versions %s", times, strings.Join(distinct, ", "))})
+		} else {
+			problems = append(problems, Problem{Entry: k.key, Message: times})
+		}
+	}
+	return problems
+}
+
+// ValidateProperties checks properties, e.g. as read by ParseProperties,
+// without a POM. Problems are returned sorted by property name.
+func ValidateProperties(properties map[string]string) []Problem {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	problems := []Problem{}
+	for _, name := range names {
+		v := properties[name]
+		switch {
+		case strings.ContainsAny(name, " \t\n${}"):
+			problems = append(problems, Problem{Entry: name, Message: "is not a valid property name"})
+		case v == "":
+			problems = append(problems, Problem{Entry: name, Message: "has no value"})
+		case isMetaVersion(v):
+			problems = append(problems, Problem{Entry: name, Message: fmt.Sprintf("uses version %s, which is deprecated and not reproducible", v)})
+		case strings.Contains(v, "${"+name+"}"):
+			problems = append(problems, Problem{Entry: name, Message: "references itself"})
+		}
+	}
+	return problems
+}
+
+// distinctStrings returns the distinct values of ss, in the order they first
+// appear.
+func distinctStrings(ss []string) []string {
+	seen := map[string]bool{}
+	var distinct []string
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s] = true
+			distinct = append(distinct, s)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidatePatches(t *testing.T) {
+	patches, err := ParsePatches("testdata/invalid-entries-patches.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Problem{
+		{Entry: "org.json:json", Message: "has no version"},
+		{Entry: "ch.qos.logback:logback-core", Message: "uses version LATEST, which is deprecated and not reproducible"},
+		{Entry: "ch.qos.logback:logback-core", Message: "uses version LATEST, which is deprecated and not reproducible"},
+		{Entry: "io.netty:netty-handler", Message: "is patched 2 times with conflicting versions 4.1.118.Final, 4.1.115.Final"},
+		{Entry: "ch.qos.logback:logback-core", Message: "is patched 2 times"},
+	}
+	if diff := cmp.Diff(want, ValidatePatches(patches)); diff != "" {
+		t.Errorf("ValidatePatches() (-want +got)\n%s", diff)
+	}
+
+	valid, err := ParsePatches("testdata/patches.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ValidatePatches(valid); len(got) != 0 {
+		t.Errorf("ValidatePatches() = %+v, want no problems", got)
+	}
+}
+
+func TestValidatePatchesEntries(t *testing.T) {
+	testCases := []struct {
+		name  string
+		patch Patch
+		want  []Problem
+	}{{
+		name:  "valid",
+		patch: Patch{GroupID: "a", ArtifactID: "b", Version: "1.0.1", From: "1.0.0"},
+		want:  []Problem{},
+	}, {
+		name:  "whitespace",
+		patch: Patch{GroupID: "a", ArtifactID: "b c", Version: "1.0.1"},
+		want:  []Problem{{Entry: "a:b c", Message: `artifactId "b c" contains whitespace`}},
+	}, {
+		name:  "from the same version",
+		patch: Patch{GroupID: "a", ArtifactID: "b", Version: "1.0.1", From: "1.0.1"},
+		want:  []Problem{{Entry: "a:b", Message: "is from 1.0.1 to the same version"}},
+	}, {
+		name:  "wildcard without from",
+		patch: Patch{GroupID: "a", ArtifactID: "*", Version: "1.0.1"},
+		want:  []Problem{{Entry: "a:*", Message: "matches every artifactId of the group but has no from"}},
+	}, {
+		name:  "renamed to itself",
+		patch: Patch{GroupID: "a", ArtifactID: "b", Version: "1.0.1", RenameTo: &Coordinate{GroupID: "a", ArtifactID: "b"}},
+		want:  []Problem{{Entry: "a:b", Message: "is renamed to itself"}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ValidatePatches([]Patch{tc.patch})); diff != "" {
+				t.Errorf("ValidatePatches() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidatePatchesDuplicates(t *testing.T) {
+	testCases := []struct {
+		name    string
+		patches []Patch
+		want    []Problem
+	}{{
+		name: "wildcards from different versions",
+		patches: []Patch{
+			{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final", From: "4.1.94.Final"},
+			{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final", From: "4.1.100.Final"},
+		},
+		want: []Problem{},
+	}, {
+		name: "wildcards from the same version",
+		patches: []Patch{
+			{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.118.Final", From: "4.1.94.Final"},
+			{GroupID: "io.netty", ArtifactID: "*", Version: "4.1.117.Final", From: "4.1.94.Final"},
+		},
+		want: []Problem{{Entry: "io.netty:*", Message: "is patched 2 times from 4.1.94.Final with conflicting versions 4.1.118.Final, 4.1.117.Final"}},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ValidatePatches(tc.patches)); diff != "" {
+				t.Errorf("ValidatePatches() (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateProperties(t *testing.T) {
+	properties := map[string]string{
+		"netty.version":   "4.1.118.Final",
+		"jackson.version": "",
+		"bad name":        "1.0.0",
+		"latest.version":  "RELEASE",
+		"self.version":    "${self.version}.1",
+	}
+	want := []Problem{
+		{Entry: "bad name", Message: "is not a valid property name"},
+		{Entry: "jackson.version", Message: "has no value"},
+		{Entry: "latest.version", Message: "uses version RELEASE, which is deprecated and not reproducible"},
+		{Entry: "self.version", Message: "references itself"},
+	}
+	if diff := cmp.Diff(want, ValidateProperties(properties)); diff != "" {
+		t.Errorf("ValidateProperties() (-want +got)\n%s", diff)
+	}
+}
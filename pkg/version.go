@@ -0,0 +1,224 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BumpPolicy restricts how far PatchProject may move an existing dependency
+// version.
+type BumpPolicy string
+
+const (
+	// BumpPolicyMajor allows any version change. This is the default.
+	BumpPolicyMajor BumpPolicy = "major"
+	// BumpPolicyMinor allows changes that keep the major version.
+	BumpPolicyMinor BumpPolicy = "minor"
+	// BumpPolicyPatch allows changes that keep the major and minor version.
+	BumpPolicyPatch BumpPolicy = "patch"
+)
+
+// ParseBumpPolicy parses a bump policy name. An empty name is the default
+// (major) policy.
+func ParseBumpPolicy(s string) (BumpPolicy, error) {
+	switch p := BumpPolicy(s); p {
+	case "":
+		return BumpPolicyMajor, nil
+	case BumpPolicyMajor, BumpPolicyMinor, BumpPolicyPatch:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid bump policy %q, must be one of: patch, minor, major", s)
+}
+
+// isMetaVersion returns true for Maven's LATEST and RELEASE versions, which
+// resolve to whatever is newest in the repository at build time. They are
+// deprecated and make builds irreproducible.
+func isMetaVersion(v string) bool {
+	return v == "LATEST" || v == "RELEASE"
+}
+
+// caseOnlyChange returns true if the versions only differ in case, e.g.
+// 1.0.Final and 1.0.final. Maven treats those as different versions, so such
+// a change is usually a copy-paste mistake rather than a bump.
+func caseOnlyChange(from, to string) bool {
+	return from != to && strings.EqualFold(from, to)
+}
+
+// compositeVersion returns the properties referenced by a version that is
+// more than a single property reference, e.g. ${netty.major}.${netty.minor}.Final.
+// Such a version can not be replaced by a literal without losing the
+// properties it is built from.
+func compositeVersion(v string) []string {
+	matches := propertyRefRe.FindAllStringSubmatch(v, -1)
+	if len(matches) == 0 || (len(matches) == 1 && matches[0][0] == v) {
+		return nil
+	}
+	refs := make([]string, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+// versionSegments returns the leading numeric segments of a Maven version,
+// e.g. 4.1.94.Final gives [4 1 94] and 2.0.0-M1 gives [2 0 0]. Returns false
+// if the version does not start with a number, which is the case for
+// properties (${foo.version}) and version ranges ([1.0,2.0)).
+func versionSegments(v string) ([]int, bool) {
+	var segments []int
+	for _, s := range strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' }) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			break
+		}
+		segments = append(segments, n)
+	}
+	return segments, len(segments) > 0
+}
+
+// segment returns the i'th segment, treating missing ones as 0 like Maven
+// does (1.2 == 1.2.0).
+func segment(segments []int, i int) int {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return 0
+}
+
+// allowedByPolicy checks whether moving from version current to version next
+// is allowed under the policy. The second return value is false if either
+// version can not be parsed, in which case the change is allowed.
+func allowedByPolicy(policy BumpPolicy, current, next string) (bool, bool) {
+	cur, ok := versionSegments(current)
+	if !ok {
+		return true, false
+	}
+	nxt, ok := versionSegments(next)
+	if !ok {
+		return true, false
+	}
+	switch policy {
+	case BumpPolicyPatch:
+		return segment(cur, 0) == segment(nxt, 0) && segment(cur, 1) == segment(nxt, 1), true
+	case BumpPolicyMinor:
+		return segment(cur, 0) == segment(nxt, 0), true
+	}
+	return true, true
+}
+
+// qualifierRank orders the well known Maven qualifiers. A release (no
+// qualifier) ranks the same as ga/final/release. Unknown qualifiers sort
+// after all of these, lexically among themselves.
+var qualifierRank = map[string]int{
+	"alpha":     1,
+	"a":         1,
+	"beta":      2,
+	"b":         2,
+	"milestone": 3,
+	"m":         3,
+	"rc":        4,
+	"cr":        4,
+	"snapshot":  5,
+	"":          6,
+	"ga":        6,
+	"final":     6,
+	"release":   6,
+	"sp":        7,
+}
+
+// versionItems splits a version into its numeric and qualifier items, e.g.
+// 4.1.94.Final gives [4 1 94 final] and 1.0-rc1 gives [1 0 rc 1].
+func versionItems(v string) []string {
+	var items []string
+	current := []rune{}
+	flush := func() {
+		if len(current) > 0 {
+			items = append(items, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	for _, r := range v {
+		if r == '.' || r == '-' || r == '_' {
+			flush()
+			continue
+		}
+		if len(current) > 0 && isDigit(current[len(current)-1]) != isDigit(r) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return items
+}
+
+// compareItems compares two version items. A missing item is "" and compares
+// like 0 against numbers and like a release against qualifiers, so that
+// 1.0 == 1.0.0 == 1.0.Final.
+func compareItems(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil && b == "":
+		return compareInts(an, 0)
+	case a == "" && bErr == nil:
+		return compareInts(0, bn)
+	case aErr == nil:
+		// Numbers are newer than qualifiers: 1.0.1 > 1.0-rc1
+		return 1
+	case bErr == nil:
+		return -1
+	}
+	ar, aKnown := qualifierRank[a]
+	br, bKnown := qualifierRank[b]
+	switch {
+	case aKnown && bKnown:
+		return compareInts(ar, br)
+	case aKnown:
+		return -1
+	case bKnown:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareVersions compares two Maven versions, returning -1, 0, or 1 if a is
+// older, the same as, or newer than b. This follows the ordering of Maven's
+// ComparableVersion for the common cases, it is not a full implementation.
+// The second return value is false if either version is not a plain version
+// (empty, a property reference, or a version range).
+func compareVersions(a, b string) (int, bool) {
+	if _, ok := versionSegments(a); !ok {
+		return 0, false
+	}
+	if _, ok := versionSegments(b); !ok {
+		return 0, false
+	}
+	ai, bi := versionItems(a), versionItems(b)
+	for i := 0; i < len(ai) || i < len(bi); i++ {
+		var x, y string
+		if i < len(ai) {
+			x = ai[i]
+		}
+		if i < len(bi) {
+			y = bi[i]
+		}
+		if c := compareItems(x, y); c != 0 {
+			return c, true
+		}
+	}
+	return 0, true
+}
@@ -0,0 +1,177 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVersionSegments(t *testing.T) {
+	testCases := []struct {
+		in     string
+		want   []int
+		wantOK bool
+	}{
+		{in: "4.1.94.Final", want: []int{4, 1, 94}, wantOK: true},
+		{in: "2.0.0-M1", want: []int{2, 0, 0}, wantOK: true},
+		{in: "20231013", want: []int{20231013}, wantOK: true},
+		{in: "9.4.53.v20231009", want: []int{9, 4, 53}, wantOK: true},
+		{in: "${netty.version}"},
+		{in: "[1.4.12,2.0.0)"},
+		{in: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, ok := versionSegments(tc.in)
+			if ok != tc.wantOK {
+				t.Errorf("versionSegments(%s) ok = %v, want %v", tc.in, ok, tc.wantOK)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("versionSegments(%s) (-want +got)\n%s", tc.in, diff)
+			}
+		})
+	}
+}
+
+func TestAllowedByPolicy(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     BumpPolicy
+		current    string
+		next       string
+		want       bool
+		wantParsed bool
+	}{{
+		name:       "patch policy, patch bump",
+		policy:     BumpPolicyPatch,
+		current:    "4.1.94.Final",
+		next:       "4.1.118.Final",
+		want:       true,
+		wantParsed: true,
+	}, {
+		name:       "patch policy, minor bump",
+		policy:     BumpPolicyPatch,
+		current:    "4.1.94.Final",
+		next:       "4.2.0.Final",
+		wantParsed: true,
+	}, {
+		name:       "patch policy, missing segment is zero",
+		policy:     BumpPolicyPatch,
+		current:    "1.2",
+		next:       "1.2.1",
+		want:       true,
+		wantParsed: true,
+	}, {
+		name:       "minor policy, minor bump",
+		policy:     BumpPolicyMinor,
+		current:    "2.15.0",
+		next:       "2.18.0",
+		want:       true,
+		wantParsed: true,
+	}, {
+		name:       "minor policy, major bump",
+		policy:     BumpPolicyMinor,
+		current:    "2.15.0",
+		next:       "3.0.0",
+		wantParsed: true,
+	}, {
+		name:       "major policy, major bump",
+		policy:     BumpPolicyMajor,
+		current:    "2.15.0",
+		next:       "3.0.0",
+		want:       true,
+		wantParsed: true,
+	}, {
+		name:    "unparseable version is allowed",
+		policy:  BumpPolicyPatch,
+		current: "${jetty.version}",
+		next:    "12.0.0",
+		want:    true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, parsed := allowedByPolicy(tc.policy, tc.current, tc.next)
+			if got != tc.want || parsed != tc.wantParsed {
+				t.Errorf("%s: allowedByPolicy(%s, %s, %s) = %v, %v, want %v, %v", tc.name, tc.policy, tc.current, tc.next, got, parsed, tc.want, tc.wantParsed)
+			}
+		})
+	}
+}
+
+func TestParseBumpPolicy(t *testing.T) {
+	for _, in := range []string{"", "major", "minor", "patch"} {
+		if _, err := ParseBumpPolicy(in); err != nil {
+			t.Errorf("ParseBumpPolicy(%q) = %v", in, err)
+		}
+	}
+	if _, err := ParseBumpPolicy("micro"); err == nil {
+		t.Errorf("ParseBumpPolicy(micro) did not fail")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{a: "4.1.94.Final", b: "4.1.118.Final", want: -1, wantOK: true},
+		{a: "4.1.118.Final", b: "4.1.94.Final", want: 1, wantOK: true},
+		{a: "1.0", b: "1.0.0", want: 0, wantOK: true},
+		{a: "1.0.Final", b: "1.0", want: 0, wantOK: true},
+		{a: "1.0-rc1", b: "1.0", want: -1, wantOK: true},
+		{a: "1.0-alpha-1", b: "1.0-beta-1", want: -1, wantOK: true},
+		{a: "1.0-SNAPSHOT", b: "1.0", want: -1, wantOK: true},
+		{a: "1.0-SNAPSHOT", b: "1.0-rc1", want: 1, wantOK: true},
+		{a: "1.0.1", b: "1.0-rc1", want: 1, wantOK: true},
+		{a: "9.4.53.v20231009", b: "9.4.52.v20230823", want: 1, wantOK: true},
+		{a: "20231013", b: "20230227", want: 1, wantOK: true},
+		{a: "${netty.version}", b: "4.1.118.Final"},
+		{a: "[1.0,2.0)", b: "1.5"},
+		{a: "", b: "1.0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.a+" vs "+tc.b, func(t *testing.T) {
+			got, ok := compareVersions(tc.a, tc.b)
+			if got != tc.want || ok != tc.wantOK {
+				t.Errorf("compareVersions(%s, %s) = %d, %v, want %d, %v", tc.a, tc.b, got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestCaseOnlyChange(t *testing.T) {
+	testCases := []struct {
+		from, to string
+		want     bool
+	}{
+		{from: "4.1.94.Final", to: "4.1.94.final", want: true},
+		{from: "5.3.0.RELEASE", to: "5.3.0.release", want: true},
+		{from: "4.1.94.Final", to: "4.1.94.Final"},
+		{from: "4.1.94.Final", to: "4.1.118.Final"},
+		{from: "4.1.94.Final", to: "4.1.118.final"},
+	}
+	for _, tc := range testCases {
+		if got := caseOnlyChange(tc.from, tc.to); got != tc.want {
+			t.Errorf("caseOnlyChange(%s, %s) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestCompositeVersion(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want []string
+	}{
+		{in: "${netty.major}.${netty.minor}.Final", want: []string{"netty.major", "netty.minor"}},
+		{in: "${netty.version}.Final", want: []string{"netty.version"}},
+		{in: "${netty.version}"},
+		{in: "4.1.118.Final"},
+		{in: ""},
+	}
+	for _, tc := range testCases {
+		if diff := cmp.Diff(tc.want, compositeVersion(tc.in)); diff != "" {
+			t.Errorf("compositeVersion(%s) (-want +got)\n%s", tc.in, diff)
+		}
+	}
+}